@@ -37,9 +37,10 @@ func (n *Identifier) Inspect(level int) string {
 }
 
 type IntegerLiteral struct {
-	Token lexer.Token // the token.INT token
-	Value int64
-	Cast  *Identifier
+	Token    lexer.Token // the token.INT token
+	Value    int64
+	Unsigned bool // Value holds the bits of a uint64 too large for an int64
+	Cast     *Identifier
 }
 
 func NewIntegerLiteral(token lexer.Token, value int64) *IntegerLiteral {
@@ -82,6 +83,28 @@ func (n *FloatLiteral) Inspect(level int) string {
 	return fmt.Sprintf("%s%T: %s\n", strings.Repeat(" ", level*2), n, n.String())
 }
 
+type CharLiteral struct {
+	Token lexer.Token // the token.RUNE token
+	Value rune
+}
+
+func NewCharLiteral(token lexer.Token, value rune) *CharLiteral {
+	return &CharLiteral{
+		Token: token,
+		Value: value,
+	}
+}
+func (n *CharLiteral) expressionNode() {}
+func (n *CharLiteral) TokenLiteral() string {
+	return n.Token.Literal
+}
+func (n *CharLiteral) String() string {
+	return "'" + n.Token.Literal + "'"
+}
+func (n *CharLiteral) Inspect(level int) string {
+	return fmt.Sprintf("%s%T: %s\n", strings.Repeat(" ", level*2), n, n.String())
+}
+
 type PrefixExpression struct {
 	Token    lexer.Token // The prefix token, e.g. !
 	Operator string
@@ -137,6 +160,83 @@ func (n *InfixExpression) Inspect(level int) string {
 	return out
 }
 
+type CastExpression struct {
+	Token lexer.Token // The 'as' token
+	Left  Expression
+	Type  *Identifier
+}
+
+func NewCastExpression(token lexer.Token, left Expression) *CastExpression {
+	return &CastExpression{
+		Token: token,
+		Left:  left,
+	}
+}
+func (n *CastExpression) expressionNode() {}
+func (n *CastExpression) TokenLiteral() string {
+	return n.Token.Literal
+}
+func (n *CastExpression) String() string {
+	return "(" + n.Left.String() + " as " + n.Type.String() + ")"
+}
+func (n *CastExpression) Inspect(level int) string {
+	var out string
+	out += fmt.Sprintf("%s%T: Type=%s\n", strings.Repeat(" ", level*2), n, n.Type.String())
+	out += n.Left.Inspect(level + 1)
+	return out
+}
+
+type AssignExpression struct {
+	Token    lexer.Token // The assignment token, e.g. = or +=
+	Target   Expression  // Identifier or IndexExpression
+	Operator string
+	Value    Expression // nil for ++ and --
+}
+
+func NewAssignExpression(token lexer.Token, target Expression) *AssignExpression {
+	return &AssignExpression{
+		Token:    token,
+		Operator: token.Literal,
+		Target:   target,
+	}
+}
+func (n *AssignExpression) expressionNode() {}
+func (n *AssignExpression) TokenLiteral() string {
+	return n.Token.Literal
+}
+func (n *AssignExpression) String() string {
+	if n.Value == nil {
+		return "(" + n.Target.String() + n.Operator + ")"
+	}
+	return "(" + n.Target.String() + " " + n.Operator + " " + n.Value.String() + ")"
+}
+func (n *AssignExpression) Inspect(level int) string {
+	var out string
+	out += fmt.Sprintf("%s%T: %s\n", strings.Repeat(" ", level*2), n, n.TokenLiteral())
+	out += n.Target.Inspect(level + 1)
+	if n.Value != nil {
+		out += n.Value.Inspect(level + 1)
+	}
+	return out
+}
+
+// AssignedIdentifier returns the variable an assignment target belongs to,
+// a for a[i].b[j], or nil if the target is not rooted in a variable.
+func AssignedIdentifier(target Expression) *Identifier {
+	for {
+		switch node := target.(type) {
+		case *Identifier:
+			return node
+		case *IndexExpression:
+			target = node.Left
+		case *FieldExpression:
+			target = node.Left
+		default:
+			return nil
+		}
+	}
+}
+
 type Boolean struct {
 	Token lexer.Token
 	Value bool
@@ -180,6 +280,26 @@ func (n *Null) Inspect(level int) string {
 	return fmt.Sprintf("%s%T: %s\n", strings.Repeat(" ", level*2), n, n.String())
 }
 
+type None struct {
+	Token lexer.Token
+}
+
+func NewNone(token lexer.Token) *None {
+	return &None{
+		Token: token,
+	}
+}
+func (n *None) expressionNode() {}
+func (n *None) TokenLiteral() string {
+	return n.Token.Literal
+}
+func (n *None) String() string {
+	return "none"
+}
+func (n *None) Inspect(level int) string {
+	return fmt.Sprintf("%s%T: %s\n", strings.Repeat(" ", level*2), n, n.String())
+}
+
 type IfExpression struct {
 	Token                  lexer.Token // The 'if' token
 	Condition              Expression
@@ -224,11 +344,73 @@ func (n *IfExpression) Inspect(level int) string {
 	return out
 }
 
+// Parameter is a function parameter, Type is nil when the parameter accepts
+// values of any type. Default is evaluated when the argument is omitted, a
+// variadic parameter collects the remaining arguments in an array.
+type Parameter struct {
+	Name     *Identifier
+	Type     *Identifier
+	Default  Expression
+	Variadic bool
+}
+
+func NewParameter(name *Identifier) *Parameter {
+	return &Parameter{
+		Name: name,
+	}
+}
+func (n *Parameter) String() string {
+	out := n.Name.String()
+	if n.Variadic {
+		out = "..." + out
+	}
+	if n.Type != nil {
+		out += ": " + n.Type.String()
+	}
+	if n.Default != nil {
+		out += " = " + n.Default.String()
+	}
+	return out
+}
+
+// ParameterIndex returns the index of the parameter name, -1 if there is
+// none.
+func ParameterIndex(parameters []*Parameter, name string) int {
+	for i, param := range parameters {
+		if param.Name.Value == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Arity describes the number of arguments accepted by a function with the
+// given parameters.
+func Arity(parameters []*Parameter) string {
+	required := 0
+	optional := false
+	for _, param := range parameters {
+		if param.Default != nil || param.Variadic {
+			optional = true
+		} else {
+			required++
+		}
+	}
+	if !optional {
+		return fmt.Sprint(required)
+	}
+	if n := len(parameters); parameters[n-1].Variadic {
+		return fmt.Sprintf("at least %d", required)
+	}
+	return fmt.Sprintf("%d to %d", required, len(parameters))
+}
+
 type FunctionLiteral struct {
-	Token      lexer.Token // The 'fn' token
-	Name       *Identifier
-	Parameters []*Identifier
-	Body       *BlockStatement
+	Token       lexer.Token // The 'fn' token
+	Name        *Identifier
+	Parameters  []*Parameter
+	ReturnTypes []*Identifier
+	Body        *BlockStatement
 }
 
 func NewFunctionLiteral(token lexer.Token) *FunctionLiteral {
@@ -250,6 +432,9 @@ func (n *FunctionLiteral) String() string {
 func (n *FunctionLiteral) Inspect(level int) string {
 	var out string
 	out += fmt.Sprintf("%s%T: Parameters=%s\n", strings.Repeat(" ", level*2), n, n.Parameters)
+	if len(n.ReturnTypes) != 0 {
+		out += fmt.Sprintf("%sReturnTypes=%s\n", strings.Repeat(" ", (level+1)*2), n.ReturnTypes)
+	}
 	out += n.Body.Inspect(level + 1)
 	return out
 }
@@ -283,6 +468,86 @@ func (n *CallExpression) Inspect(level int) string {
 	return out
 }
 
+// NamedArgument is an argument passed by name, f(x: 1)
+type NamedArgument struct {
+	Token lexer.Token // the name token
+	Name  *Identifier
+	Value Expression
+}
+
+func NewNamedArgument(token lexer.Token) *NamedArgument {
+	return &NamedArgument{
+		Token: token,
+		Name:  NewIdentifier(token),
+	}
+}
+func (n *NamedArgument) expressionNode() {}
+func (n *NamedArgument) TokenLiteral() string {
+	return n.Token.Literal
+}
+func (n *NamedArgument) String() string {
+	return n.Name.String() + ": " + n.Value.String()
+}
+func (n *NamedArgument) Inspect(level int) string {
+	var out string
+	out += fmt.Sprintf("%s%T: Name=%s\n", strings.Repeat(" ", level*2), n, n.Name.String())
+	out += n.Value.Inspect(level + 1)
+	return out
+}
+
+// SpreadExpression passes the elements of an array as separate arguments,
+// f(...xs)
+type SpreadExpression struct {
+	Token lexer.Token // the ... token
+	Value Expression
+}
+
+func NewSpreadExpression(token lexer.Token) *SpreadExpression {
+	return &SpreadExpression{
+		Token: token,
+	}
+}
+func (n *SpreadExpression) expressionNode() {}
+func (n *SpreadExpression) TokenLiteral() string {
+	return n.Token.Literal
+}
+func (n *SpreadExpression) String() string {
+	return "..." + n.Value.String()
+}
+func (n *SpreadExpression) Inspect(level int) string {
+	var out string
+	out += fmt.Sprintf("%s%T\n", strings.Repeat(" ", level*2), n)
+	out += n.Value.Inspect(level + 1)
+	return out
+}
+
+// TryExpression unwraps an ok result or some option, and returns an err
+// result or none from the enclosing function, f()?
+type TryExpression struct {
+	Token lexer.Token // the ? token
+	Value Expression
+}
+
+func NewTryExpression(token lexer.Token, value Expression) *TryExpression {
+	return &TryExpression{
+		Token: token,
+		Value: value,
+	}
+}
+func (n *TryExpression) expressionNode() {}
+func (n *TryExpression) TokenLiteral() string {
+	return n.Token.Literal
+}
+func (n *TryExpression) String() string {
+	return n.Value.String() + "?"
+}
+func (n *TryExpression) Inspect(level int) string {
+	var out string
+	out += fmt.Sprintf("%s%T\n", strings.Repeat(" ", level*2), n)
+	out += n.Value.Inspect(level + 1)
+	return out
+}
+
 type StringLiteral struct {
 	Token lexer.Token
 	Value string
@@ -375,6 +640,96 @@ func (n *IndexExpression) Inspect(level int) string {
 	return fmt.Sprintf("%s%T: %s\n", strings.Repeat(" ", level*2), n, n.String())
 }
 
+// FieldExpression reads the field Field of the struct Left.
+type FieldExpression struct {
+	Token lexer.Token // The '.' token
+	Left  Expression
+	Field *Identifier
+}
+
+func NewFieldExpression(token lexer.Token, left Expression) *FieldExpression {
+	return &FieldExpression{
+		Token: token,
+		Left:  left,
+	}
+}
+func (n *FieldExpression) expressionNode() {}
+func (n *FieldExpression) TokenLiteral() string {
+	return n.Token.Literal
+}
+func (n *FieldExpression) String() string {
+	return "(" + n.Left.String() + "." + n.Field.String() + ")"
+}
+func (n *FieldExpression) Inspect(level int) string {
+	return fmt.Sprintf("%s%T: %s\n", strings.Repeat(" ", level*2), n, n.String())
+}
+
+// StructLiteral builds a value of the struct type Name, Fields holds the
+// values of its fields in the order they are written.
+type StructLiteral struct {
+	Token  lexer.Token // the token of the struct name
+	Name   *Identifier
+	Fields []*FieldValue
+}
+
+type FieldValue struct {
+	Name  *Identifier
+	Value Expression
+}
+
+func NewStructLiteral(token lexer.Token) *StructLiteral {
+	return &StructLiteral{
+		Token: token,
+		Name:  NewIdentifier(token),
+	}
+}
+func (n *StructLiteral) expressionNode() {}
+func (n *StructLiteral) TokenLiteral() string {
+	return n.Token.Literal
+}
+func (n *StructLiteral) String() string {
+	fields := []string{}
+	for _, field := range n.Fields {
+		fields = append(fields, field.Name.String()+": "+field.Value.String())
+	}
+	return n.Name.String() + "{" + strings.Join(fields, ", ") + "}"
+}
+func (n *StructLiteral) Inspect(level int) string {
+	return fmt.Sprintf("%s%T: %s\n", strings.Repeat(" ", level*2), n, n.String())
+}
+
+// TupleLiteral holds the comma separated values of a return statement or of
+// the right-hand side of a destructuring let.
+type TupleLiteral struct {
+	Token    lexer.Token // the token of the first element
+	Elements []Expression
+}
+
+func NewTupleLiteral(token lexer.Token) *TupleLiteral {
+	return &TupleLiteral{
+		Token: token,
+	}
+}
+func (n *TupleLiteral) expressionNode() {}
+func (n *TupleLiteral) TokenLiteral() string {
+	return n.Token.Literal
+}
+func (n *TupleLiteral) String() string {
+	elements := []string{}
+	for _, e := range n.Elements {
+		elements = append(elements, e.String())
+	}
+	return strings.Join(elements, ", ")
+}
+func (n *TupleLiteral) Inspect(level int) string {
+	var out string
+	out += fmt.Sprintf("%s%T\n", strings.Repeat(" ", level*2), n)
+	for _, e := range n.Elements {
+		out += e.Inspect(level + 1)
+	}
+	return out
+}
+
 type HashLiteral struct {
 	Token lexer.Token // The '{' token
 	Pairs map[Expression]Expression
@@ -402,6 +757,7 @@ func (n *HashLiteral) Inspect(level int) string {
 
 type LoopStatement struct {
 	Token          lexer.Token // the { token
+	Label          *Identifier
 	WhileCondition Expression
 	UntilCondition Expression
 	Body           *BlockStatement
@@ -418,11 +774,15 @@ func (n *LoopStatement) TokenLiteral() string {
 	return n.Token.Literal
 }
 func (n *LoopStatement) String() string {
-	return n.Token.Literal + " " + n.Body.String()
+	return labelString(n.Label) + n.Token.Literal + " " + n.Body.String()
 }
 func (n *LoopStatement) Inspect(level int) string {
 	var out string
 	out += fmt.Sprintf("%s%T\n", strings.Repeat(" ", level*2), n)
+	if n.Label != nil {
+		out += strings.Repeat(" ", (level+1)*2) + "Label:\n"
+		out += n.Label.Inspect(level + 2)
+	}
 	if n.WhileCondition != nil {
 		out += strings.Repeat(" ", (level+1)*2) + "WhileCondition:\n"
 		out += n.WhileCondition.Inspect(level + 2)
@@ -442,7 +802,9 @@ func (n *LoopStatement) Inspect(level int) string {
 
 type ForStatement struct {
 	Token    lexer.Token // the { token
+	Label    *Identifier
 	Variable Expression
+	Value    Expression
 	Iterable Expression
 	Body     *BlockStatement
 }
@@ -458,14 +820,22 @@ func (n *ForStatement) TokenLiteral() string {
 	return n.Token.Literal
 }
 func (n *ForStatement) String() string {
-	return n.Token.Literal + " " + n.Body.String()
+	return labelString(n.Label) + n.Token.Literal + " " + n.Body.String()
 }
 func (n *ForStatement) Inspect(level int) string {
 	var out string
 	out += fmt.Sprintf("%s%T\n", strings.Repeat(" ", level*2), n)
+	if n.Label != nil {
+		out += strings.Repeat(" ", (level+1)*2) + "Label:\n"
+		out += n.Label.Inspect(level + 2)
+	}
 
 	out += strings.Repeat(" ", (level+1)*2) + "Variable:\n"
 	out += n.Variable.Inspect(level + 2)
+	if n.Value != nil {
+		out += strings.Repeat(" ", (level+1)*2) + "Value:\n"
+		out += n.Value.Inspect(level + 2)
+	}
 	if n.Iterable != nil {
 		out += strings.Repeat(" ", (level+1)*2) + "Iterable:\n"
 		out += n.Iterable.Inspect(level + 2)
@@ -473,6 +843,47 @@ func (n *ForStatement) Inspect(level int) string {
 	return out
 }
 
+// WithStatement binds Name to Resource for the duration of Body, the
+// resource is released when Body exits.
+type WithStatement struct {
+	Token    lexer.Token // the 'with' token
+	Resource Expression
+	Name     *Identifier
+	Body     *BlockStatement
+}
+
+func NewWithStatement(token lexer.Token) *WithStatement {
+	return &WithStatement{
+		Token: token,
+	}
+}
+
+func (n *WithStatement) expressionNode() {}
+func (n *WithStatement) TokenLiteral() string {
+	return n.Token.Literal
+}
+func (n *WithStatement) String() string {
+	return n.Token.Literal + " " + n.Resource.String() + " as " + n.Name.String() + " " + n.Body.String()
+}
+func (n *WithStatement) Inspect(level int) string {
+	var out string
+	out += fmt.Sprintf("%s%T\n", strings.Repeat(" ", level*2), n)
+	out += strings.Repeat(" ", (level+1)*2) + "Resource:\n"
+	out += n.Resource.Inspect(level + 2)
+	out += strings.Repeat(" ", (level+1)*2) + "Name:\n"
+	out += n.Name.Inspect(level + 2)
+	out += strings.Repeat(" ", (level+1)*2) + "Body:\n"
+	out += n.Body.Inspect(level + 2)
+	return out
+}
+
+func labelString(label *Identifier) string {
+	if label == nil {
+		return ""
+	}
+	return label.String() + ": "
+}
+
 type MatchExpression struct {
 	Token       lexer.Token // The 'if' token
 	Condition   Expression
@@ -515,8 +926,8 @@ func (n *MatchExpression) Inspect(level int) string {
 }
 
 type CaseExpression struct {
-	Token       lexer.Token // The 'if' token
-	Condition   Expression
+	Token       lexer.Token // The first token of the pattern
+	Pattern     Pattern
 	Guard       Expression
 	Consequence *BlockStatement
 }
@@ -531,14 +942,18 @@ func (n *CaseExpression) TokenLiteral() string {
 	return n.Token.Literal
 }
 func (n *CaseExpression) String() string {
-	out := "case " + n.Condition.String() + " " + n.Consequence.String()
+	out := "case " + n.Pattern.String() + " "
+	if n.Guard != nil {
+		out += "if " + n.Guard.String() + " "
+	}
+	out += n.Consequence.String()
 	return out
 }
 func (n *CaseExpression) Inspect(level int) string {
 	var out string
 	out += fmt.Sprintf("%s%T\n", strings.Repeat(" ", level*2), n)
-	out += strings.Repeat(" ", (level+1)*2) + "Condition:\n"
-	out += n.Condition.Inspect(level + 2)
+	out += strings.Repeat(" ", (level+1)*2) + "Pattern:\n"
+	out += n.Pattern.Inspect(level + 2)
 	if n.Guard != nil {
 		out += strings.Repeat(" ", (level+1)*2) + "Guard:\n"
 		out += n.Guard.Inspect(level + 2)
@@ -0,0 +1,248 @@
+package ast
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/poolpOrg/julu/lexer"
+)
+
+type Pattern interface {
+	Node
+	patternNode()
+	Inspect(level int) string
+}
+
+type WildcardPattern struct {
+	Token lexer.Token // the _ token
+}
+
+func NewWildcardPattern(token lexer.Token) *WildcardPattern {
+	return &WildcardPattern{
+		Token: token,
+	}
+}
+func (n *WildcardPattern) patternNode() {}
+func (n *WildcardPattern) TokenLiteral() string {
+	return n.Token.Literal
+}
+func (n *WildcardPattern) String() string {
+	return "_"
+}
+func (n *WildcardPattern) Inspect(level int) string {
+	return fmt.Sprintf("%s%T\n", strings.Repeat(" ", level*2), n)
+}
+
+type BindingPattern struct {
+	Token lexer.Token // the token.IDENTIFIER token
+	Name  *Identifier
+}
+
+func NewBindingPattern(token lexer.Token) *BindingPattern {
+	return &BindingPattern{
+		Token: token,
+		Name:  NewIdentifier(token),
+	}
+}
+func (n *BindingPattern) patternNode() {}
+func (n *BindingPattern) TokenLiteral() string {
+	return n.Token.Literal
+}
+func (n *BindingPattern) String() string {
+	return n.Name.String()
+}
+func (n *BindingPattern) Inspect(level int) string {
+	return fmt.Sprintf("%s%T: Name=%s\n", strings.Repeat(" ", level*2), n, n.String())
+}
+
+type LiteralPattern struct {
+	Token lexer.Token // the first token of the literal
+	Value Expression
+}
+
+func NewLiteralPattern(token lexer.Token, value Expression) *LiteralPattern {
+	return &LiteralPattern{
+		Token: token,
+		Value: value,
+	}
+}
+func (n *LiteralPattern) patternNode() {}
+func (n *LiteralPattern) TokenLiteral() string {
+	return n.Token.Literal
+}
+func (n *LiteralPattern) String() string {
+	return n.Value.String()
+}
+func (n *LiteralPattern) Inspect(level int) string {
+	var out string
+	out += fmt.Sprintf("%s%T\n", strings.Repeat(" ", level*2), n)
+	out += n.Value.Inspect(level + 1)
+	return out
+}
+
+type RangePattern struct {
+	Token     lexer.Token // the .. or ..= token
+	Low       Expression
+	High      Expression
+	Inclusive bool
+}
+
+func NewRangePattern(token lexer.Token, low Expression) *RangePattern {
+	return &RangePattern{
+		Token:     token,
+		Low:       low,
+		Inclusive: token.Type == lexer.DOTDOT_EQUAL,
+	}
+}
+func (n *RangePattern) patternNode() {}
+func (n *RangePattern) TokenLiteral() string {
+	return n.Token.Literal
+}
+func (n *RangePattern) String() string {
+	return n.Low.String() + n.Token.Literal + n.High.String()
+}
+func (n *RangePattern) Inspect(level int) string {
+	var out string
+	out += fmt.Sprintf("%s%T: Inclusive=%t\n", strings.Repeat(" ", level*2), n, n.Inclusive)
+	out += strings.Repeat(" ", (level+1)*2) + "Low:\n"
+	out += n.Low.Inspect(level + 2)
+	out += strings.Repeat(" ", (level+1)*2) + "High:\n"
+	out += n.High.Inspect(level + 2)
+	return out
+}
+
+type ArrayPattern struct {
+	Token    lexer.Token // the [ token
+	Elements []Pattern
+	Rest     Pattern // matches the remaining elements as an array, nil if absent
+}
+
+func NewArrayPattern(token lexer.Token) *ArrayPattern {
+	return &ArrayPattern{
+		Token: token,
+	}
+}
+func (n *ArrayPattern) patternNode() {}
+func (n *ArrayPattern) TokenLiteral() string {
+	return n.Token.Literal
+}
+func (n *ArrayPattern) String() string {
+	elements := []string{}
+	for _, e := range n.Elements {
+		elements = append(elements, e.String())
+	}
+	if n.Rest != nil {
+		elements = append(elements, "..."+n.Rest.String())
+	}
+	return "[" + strings.Join(elements, ", ") + "]"
+}
+func (n *ArrayPattern) Inspect(level int) string {
+	var out string
+	out += fmt.Sprintf("%s%T\n", strings.Repeat(" ", level*2), n)
+	for _, e := range n.Elements {
+		out += e.Inspect(level + 1)
+	}
+	if n.Rest != nil {
+		out += strings.Repeat(" ", (level+1)*2) + "Rest:\n"
+		out += n.Rest.Inspect(level + 2)
+	}
+	return out
+}
+
+type HashPattern struct {
+	Token  lexer.Token // the { token
+	Keys   []Expression
+	Values []Pattern
+}
+
+func NewHashPattern(token lexer.Token) *HashPattern {
+	return &HashPattern{
+		Token: token,
+	}
+}
+func (n *HashPattern) patternNode() {}
+func (n *HashPattern) TokenLiteral() string {
+	return n.Token.Literal
+}
+func (n *HashPattern) String() string {
+	pairs := []string{}
+	for i, key := range n.Keys {
+		pairs = append(pairs, key.String()+": "+n.Values[i].String())
+	}
+	return "{" + strings.Join(pairs, ", ") + "}"
+}
+func (n *HashPattern) Inspect(level int) string {
+	var out string
+	out += fmt.Sprintf("%s%T\n", strings.Repeat(" ", level*2), n)
+	for i, key := range n.Keys {
+		out += strings.Repeat(" ", (level+1)*2) + "Key:\n"
+		out += key.Inspect(level + 2)
+		out += strings.Repeat(" ", (level+1)*2) + "Value:\n"
+		out += n.Values[i].Inspect(level + 2)
+	}
+	return out
+}
+
+// VariantPattern matches a value built by a variant constructor, such as
+// ok(v) or some(v), and its arguments.
+type VariantPattern struct {
+	Token     lexer.Token // the token.IDENTIFIER token
+	Name      *Identifier
+	Arguments []Pattern
+}
+
+func NewVariantPattern(token lexer.Token) *VariantPattern {
+	return &VariantPattern{
+		Token: token,
+		Name:  NewIdentifier(token),
+	}
+}
+func (n *VariantPattern) patternNode() {}
+func (n *VariantPattern) TokenLiteral() string {
+	return n.Token.Literal
+}
+func (n *VariantPattern) String() string {
+	arguments := []string{}
+	for _, a := range n.Arguments {
+		arguments = append(arguments, a.String())
+	}
+	return n.Name.String() + "(" + strings.Join(arguments, ", ") + ")"
+}
+func (n *VariantPattern) Inspect(level int) string {
+	var out string
+	out += fmt.Sprintf("%s%T: Name=%s\n", strings.Repeat(" ", level*2), n, n.Name.String())
+	for _, a := range n.Arguments {
+		out += a.Inspect(level + 1)
+	}
+	return out
+}
+
+type AlternativePattern struct {
+	Token        lexer.Token // the first token of the first alternative
+	Alternatives []Pattern
+}
+
+func NewAlternativePattern(token lexer.Token) *AlternativePattern {
+	return &AlternativePattern{
+		Token: token,
+	}
+}
+func (n *AlternativePattern) patternNode() {}
+func (n *AlternativePattern) TokenLiteral() string {
+	return n.Token.Literal
+}
+func (n *AlternativePattern) String() string {
+	alternatives := []string{}
+	for _, a := range n.Alternatives {
+		alternatives = append(alternatives, a.String())
+	}
+	return strings.Join(alternatives, " | ")
+}
+func (n *AlternativePattern) Inspect(level int) string {
+	var out string
+	out += fmt.Sprintf("%s%T\n", strings.Repeat(" ", level*2), n)
+	for _, a := range n.Alternatives {
+		out += a.Inspect(level + 1)
+	}
+	return out
+}
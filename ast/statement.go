@@ -14,10 +14,12 @@ type Statement interface {
 }
 
 type LetStatement struct {
-	Token lexer.Token // the token.LET token
-	Name  *Identifier
-	Type  *Identifier
-	Value Expression
+	Token   lexer.Token // the token.LET token
+	Mutable bool
+	Name    *Identifier
+	Names   []*Identifier // the names of a destructuring let, Name is nil then
+	Type    *Identifier
+	Value   Expression
 }
 
 func NewLetStatement(token lexer.Token) *LetStatement {
@@ -30,14 +32,27 @@ func (n *LetStatement) TokenLiteral() string {
 	return n.Token.Literal
 }
 func (n *LetStatement) String() string {
-	return n.Token.Literal + " " + n.Name.String() + " = " + n.Value.String() + ";"
+	if n.Mutable {
+		return n.Token.Literal + " mut " + n.names() + " = " + n.Value.String() + ";"
+	}
+	return n.Token.Literal + " " + n.names() + " = " + n.Value.String() + ";"
 }
 func (n *LetStatement) Inspect(level int) string {
 	var out string
-	out += fmt.Sprintf("%s%T: Name=%s\n", strings.Repeat(" ", level*2), n, n.Name.String())
+	out += fmt.Sprintf("%s%T: Name=%s, Mutable=%t\n", strings.Repeat(" ", level*2), n, n.names(), n.Mutable)
 	out += n.Value.Inspect(level + 1)
 	return out
 }
+func (n *LetStatement) names() string {
+	if n.Name != nil {
+		return n.Name.String()
+	}
+	names := []string{}
+	for _, name := range n.Names {
+		names = append(names, name.String())
+	}
+	return strings.Join(names, ", ")
+}
 
 type ReturnStatement struct {
 	Token       lexer.Token // the token.RETURN token
@@ -63,6 +78,168 @@ func (n *ReturnStatement) Inspect(level int) string {
 	return out
 }
 
+// DeferStatement schedules a call to run when the enclosing block exits.
+type DeferStatement struct {
+	Token lexer.Token // the token.DEFER token
+	Call  Expression
+}
+
+func NewDeferStatement(token lexer.Token) *DeferStatement {
+	return &DeferStatement{
+		Token: token,
+	}
+}
+func (n *DeferStatement) statementNode() {}
+func (n *DeferStatement) TokenLiteral() string {
+	return n.Token.Literal
+}
+func (n *DeferStatement) String() string {
+	return n.Token.Literal + " " + n.Call.String() + ";"
+}
+func (n *DeferStatement) Inspect(level int) string {
+	var out string
+	out += fmt.Sprintf("%s%T\n", strings.Repeat(" ", level*2), n)
+	out += n.Call.Inspect(level + 1)
+	return out
+}
+
+// StructStatement declares a struct type Name, whose fields are listed in
+// declaration order with their type.
+type StructStatement struct {
+	Token  lexer.Token // the token.STRUCT token
+	Name   *Identifier
+	Fields []*Parameter
+}
+
+func NewStructStatement(token lexer.Token) *StructStatement {
+	return &StructStatement{
+		Token: token,
+	}
+}
+func (n *StructStatement) statementNode() {}
+func (n *StructStatement) TokenLiteral() string {
+	return n.Token.Literal
+}
+func (n *StructStatement) String() string {
+	fields := []string{}
+	for _, field := range n.Fields {
+		fields = append(fields, field.String())
+	}
+	return n.Token.Literal + " " + n.Name.String() + " { " + strings.Join(fields, ", ") + " }"
+}
+func (n *StructStatement) Inspect(level int) string {
+	return fmt.Sprintf("%s%T: %s\n", strings.Repeat(" ", level*2), n, n.String())
+}
+
+// UnionStatement declares a tagged union Name, a value of the union holds
+// one of its variants.
+type UnionStatement struct {
+	Token    lexer.Token // the token.UNION token
+	Name     *Identifier
+	Variants []*UnionVariant
+}
+
+// UnionVariant is a variant of a union, with the fields it holds in
+// declaration order. Fields is nil for a variant declared without
+// parentheses.
+type UnionVariant struct {
+	Name   *Identifier
+	Fields []*Parameter
+}
+
+func (v *UnionVariant) String() string {
+	if v.Fields == nil {
+		return v.Name.String()
+	}
+	fields := []string{}
+	for _, field := range v.Fields {
+		fields = append(fields, field.String())
+	}
+	return v.Name.String() + "(" + strings.Join(fields, ", ") + ")"
+}
+
+func NewUnionStatement(token lexer.Token) *UnionStatement {
+	return &UnionStatement{
+		Token: token,
+	}
+}
+func (n *UnionStatement) statementNode() {}
+func (n *UnionStatement) TokenLiteral() string {
+	return n.Token.Literal
+}
+func (n *UnionStatement) String() string {
+	variants := []string{}
+	for _, variant := range n.Variants {
+		variants = append(variants, variant.String())
+	}
+	return n.Token.Literal + " " + n.Name.String() + " { " + strings.Join(variants, ", ") + " }"
+}
+func (n *UnionStatement) Inspect(level int) string {
+	return fmt.Sprintf("%s%T: %s\n", strings.Repeat(" ", level*2), n, n.String())
+}
+
+// TypeStatement declares the type Name, an alias of Type if Alias is set and
+// a distinct type represented by Type otherwise.
+type TypeStatement struct {
+	Token lexer.Token // the token.TYPE token
+	Name  *Identifier
+	Alias bool
+	Type  *Identifier
+}
+
+func NewTypeStatement(token lexer.Token) *TypeStatement {
+	return &TypeStatement{
+		Token: token,
+	}
+}
+func (n *TypeStatement) statementNode() {}
+func (n *TypeStatement) TokenLiteral() string {
+	return n.Token.Literal
+}
+func (n *TypeStatement) String() string {
+	if n.Alias {
+		return n.Token.Literal + " " + n.Name.String() + " = " + n.Type.String()
+	}
+	return n.Token.Literal + " " + n.Name.String() + " " + n.Type.String()
+}
+func (n *TypeStatement) Inspect(level int) string {
+	return fmt.Sprintf("%s%T: %s\n", strings.Repeat(" ", level*2), n, n.String())
+}
+
+// MethodBlockStatement binds the methods it declares to the type Type, each
+// method takes the value it is called on as its first parameter, self.
+type MethodBlockStatement struct {
+	Token   lexer.Token // the token of the type name
+	Type    *Identifier
+	Methods []*FunctionLiteral
+}
+
+func NewMethodBlockStatement(token lexer.Token) *MethodBlockStatement {
+	return &MethodBlockStatement{
+		Token: token,
+		Type:  NewIdentifier(token),
+	}
+}
+func (n *MethodBlockStatement) statementNode() {}
+func (n *MethodBlockStatement) TokenLiteral() string {
+	return n.Token.Literal
+}
+func (n *MethodBlockStatement) String() string {
+	methods := []string{}
+	for _, method := range n.Methods {
+		methods = append(methods, method.Name.String())
+	}
+	return n.Type.String() + " => { " + strings.Join(methods, ", ") + " }"
+}
+func (n *MethodBlockStatement) Inspect(level int) string {
+	var out string
+	out += fmt.Sprintf("%s%T: %s\n", strings.Repeat(" ", level*2), n, n.Type.String())
+	for _, method := range n.Methods {
+		out += method.Inspect(level + 1)
+	}
+	return out
+}
+
 type ExpressionStatement struct {
 	Token      lexer.Token // the first token of the expression
 	Expression Expression
@@ -174,6 +351,8 @@ func (n *MatchBlockStatement) Inspect(level int) string {
 
 type BreakStatement struct {
 	Token lexer.Token // the token.BREAK token
+	Label *Identifier
+	Value Expression
 }
 
 func NewBreakStatement(token lexer.Token) *BreakStatement {
@@ -186,16 +365,32 @@ func (n *BreakStatement) TokenLiteral() string {
 	return n.Token.Literal
 }
 func (n *BreakStatement) String() string {
-	return n.Token.Literal
+	out := n.Token.Literal
+	if n.Label != nil {
+		out += " " + n.Label.String()
+	}
+	if n.Value != nil {
+		out += " " + n.Value.String()
+	}
+	return out
 }
 func (n *BreakStatement) Inspect(level int) string {
 	var out string
 	out += fmt.Sprintf("%s%T\n", strings.Repeat(" ", level*2), n)
+	if n.Label != nil {
+		out += strings.Repeat(" ", (level+1)*2) + "Label:\n"
+		out += n.Label.Inspect(level + 2)
+	}
+	if n.Value != nil {
+		out += strings.Repeat(" ", (level+1)*2) + "Value:\n"
+		out += n.Value.Inspect(level + 2)
+	}
 	return out
 }
 
 type ContinueStatement struct {
-	Token lexer.Token // the token.BREAK token
+	Token lexer.Token // the token.CONTINUE token
+	Label *Identifier
 }
 
 func NewContinueStatement(token lexer.Token) *ContinueStatement {
@@ -208,11 +403,19 @@ func (n *ContinueStatement) TokenLiteral() string {
 	return n.Token.Literal
 }
 func (n *ContinueStatement) String() string {
-	return n.Token.Literal
+	out := n.Token.Literal
+	if n.Label != nil {
+		out += " " + n.Label.String()
+	}
+	return out
 }
 func (n *ContinueStatement) Inspect(level int) string {
 	var out string
 	out += fmt.Sprintf("%s%T\n", strings.Repeat(" ", level*2), n)
+	if n.Label != nil {
+		out += strings.Repeat(" ", (level+1)*2) + "Label:\n"
+		out += n.Label.Inspect(level + 2)
+	}
 	return out
 }
 
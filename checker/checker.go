@@ -0,0 +1,762 @@
+// Package checker walks a parsed program before it runs. It resolves
+// identifiers and infers the type of expressions from literals, typed
+// declarations and function signatures, and reports the mismatches the
+// evaluator would otherwise only find at runtime.
+//
+// The checker only reports what it can prove: an expression whose type it
+// can't infer, such as the result of a call to a function without a return
+// signature, is accepted anywhere and left to the evaluator to check.
+package checker
+
+import (
+	"fmt"
+
+	"github.com/poolpOrg/julu/ast"
+	"github.com/poolpOrg/julu/evaluator"
+	"github.com/poolpOrg/julu/lexer"
+	"github.com/poolpOrg/julu/object"
+)
+
+type checker struct {
+	scope   *scope
+	fn      *function
+	methods map[Type]map[string]*ast.FunctionLiteral
+	types   map[ast.Expression]Type
+	hoisted map[ast.Statement]bool
+	errors  []string
+}
+
+// function is the function being checked, owner is the type it is bound to
+// for a method.
+type function struct {
+	literal *ast.FunctionLiteral
+	owner   Type
+}
+
+func (f *function) name() string {
+	if f.literal.Name == nil {
+		return "anonymous function"
+	}
+	if f.owner != unknown {
+		return string(f.owner) + "." + f.literal.Name.Value
+	}
+	return f.literal.Name.Value
+}
+
+// Check returns the errors found in program, formatted like the errors of
+// the parser.
+func Check(program *ast.Program) []string {
+	c := &checker{
+		scope:   newScope(nil),
+		methods: make(map[Type]map[string]*ast.FunctionLiteral),
+		types:   make(map[ast.Expression]Type),
+		hoisted: make(map[ast.Statement]bool),
+	}
+	c.statements(program.Statements)
+	return c.errors
+}
+
+func (c *checker) errorf(token lexer.Token, format string, a ...interface{}) {
+	pos := token.Position()
+	c.errors = append(c.errors, fmt.Sprintf("[%d:%d] %s", pos.Line(), pos.Column(), fmt.Sprintf(format, a...)))
+}
+
+// statements checks a list of statements evaluated in the current scope and
+// returns the type of the last one, which is the value of a block.
+func (c *checker) statements(statements []ast.Statement) Type {
+	c.hoist(statements)
+
+	result := unknown
+	for _, statement := range statements {
+		result = c.statement(statement)
+	}
+	return result
+}
+
+// hoist declares the names bound by statements before they are checked, so
+// that functions can refer to names bound after them. A name bound more than
+// once in a scope has no static type.
+func (c *checker) hoist(statements []ast.Statement) {
+	for _, statement := range statements {
+		if c.hoisted[statement] {
+			continue
+		}
+		c.hoisted[statement] = true
+
+		switch stmt := statement.(type) {
+		case *ast.LetStatement:
+			if stmt.Names != nil {
+				for _, name := range stmt.Names {
+					c.scope.declare(name.Value)
+				}
+			} else {
+				c.scope.declare(stmt.Name.Value)
+			}
+
+		case *ast.ExpressionStatement:
+			if fn, ok := stmt.Expression.(*ast.FunctionLiteral); ok && fn.Name != nil {
+				c.scope.declare(fn.Name.Value).function = fn
+			}
+
+		case *ast.StructStatement:
+			c.scope.declare(stmt.Name.Value).definition = stmt
+
+		case *ast.UnionStatement:
+			c.scope.declare(stmt.Name.Value).definition = stmt
+			for _, variant := range stmt.Variants {
+				sym := c.scope.declare(variant.Name.Value)
+				sym.union, sym.variant = Type(stmt.Name.Value), variant
+			}
+
+		case *ast.TypeStatement:
+			c.scope.declare(stmt.Name.Value).definition = stmt
+
+		case *ast.MethodBlockStatement:
+			owner := c.resolve(stmt.Type.Value)
+			if owner == unknown {
+				continue
+			}
+			if c.methods[owner] == nil {
+				c.methods[owner] = make(map[string]*ast.FunctionLiteral)
+			}
+			for _, method := range stmt.Methods {
+				c.methods[owner][method.Name.Value] = method
+			}
+		}
+	}
+}
+
+func (c *checker) statement(statement ast.Statement) Type {
+	switch node := statement.(type) {
+	case *ast.LetStatement:
+		c.letStatement(node)
+
+	case *ast.ReturnStatement:
+		if node.ReturnValue != nil {
+			c.expression(node.ReturnValue)
+			c.checkReturnValue(node.Token, node.ReturnValue)
+		}
+
+	case *ast.DeferStatement:
+		c.expression(node.Call)
+
+	case *ast.TypeStatement:
+		if c.typeCycle(node) == node {
+			c.errorf(node.Name.Token, "invalid recursive type: %s", node.Name.Value)
+		}
+
+	case *ast.MethodBlockStatement:
+		owner := c.resolve(node.Type.Value)
+		for _, method := range node.Methods {
+			c.function(method, owner)
+		}
+
+	case *ast.BreakStatement:
+		if node.Value != nil {
+			c.expression(node.Value)
+		}
+
+	case *ast.BlockStatement:
+		return c.statements(node.Statements)
+
+	case *ast.ExpressionStatement:
+		if node.Expression != nil {
+			return c.expression(node.Expression)
+		}
+	}
+	return unknown
+}
+
+func (c *checker) letStatement(node *ast.LetStatement) {
+	val := c.expression(node.Value)
+	if node.Names != nil {
+		return
+	}
+
+	sym := c.scope.local(node.Name.Value)
+	// a mutable binding can be assigned another function later on
+	if fn, ok := node.Value.(*ast.FunctionLiteral); ok && !node.Mutable {
+		sym.function = fn
+	}
+	if node.Type != nil {
+		if msg := c.typeError(val, node.Type.Value); msg != "" {
+			c.errorf(node.Token, "%s", msg)
+		}
+		val = c.resolve(node.Type.Value)
+	}
+	if val != nullType {
+		sym.typ = val
+	}
+}
+
+// block checks a block evaluated in a scope of its own.
+func (c *checker) block(block *ast.BlockStatement, bind func()) Type {
+	c.scope = newScope(c.scope)
+	defer func() { c.scope = c.scope.parent }()
+
+	if bind != nil {
+		bind()
+	}
+	return c.statements(block.Statements)
+}
+
+// function checks the body of fn, owner is the type a method is bound to.
+func (c *checker) function(fn *ast.FunctionLiteral, owner Type) {
+	outer := c.fn
+	c.fn = &function{literal: fn, owner: owner}
+	defer func() { c.fn = outer }()
+
+	var last *ast.ExpressionStatement
+	if n := len(fn.Body.Statements); n != 0 {
+		last, _ = fn.Body.Statements[n-1].(*ast.ExpressionStatement)
+	}
+
+	c.block(fn.Body, func() {
+		for _, param := range fn.Parameters {
+			if param.Default != nil {
+				val := c.expression(param.Default)
+				if param.Type != nil {
+					if msg := c.typeError(val, param.Type.Value); msg != "" {
+						c.errorf(param.Name.Token, "argument %s of %s: %s", param.Name.Value, c.fn.name(), msg)
+					}
+				}
+			}
+
+			sym := c.scope.declare(param.Name.Value)
+			switch {
+			case param.Name.Token.Type == lexer.SELF:
+				sym.typ = owner
+			case param.Type != nil && param.Variadic:
+				sym.typ = arrayOf(c.resolve(param.Type.Value))
+			case param.Variadic:
+				sym.typ = arrayOf(unknown)
+			case param.Type != nil:
+				sym.typ = c.resolve(param.Type.Value)
+			}
+		}
+	})
+
+	// the value of the last expression of the body is returned
+	if last != nil && last.Expression != nil {
+		c.checkReturnValue(last.Token, last.Expression)
+	}
+}
+
+// checkReturnValue checks a value returned by the current function against
+// its return signature.
+func (c *checker) checkReturnValue(token lexer.Token, value ast.Expression) {
+	if c.fn == nil || len(c.fn.literal.ReturnTypes) == 0 {
+		return
+	}
+	returnTypes := c.fn.literal.ReturnTypes
+
+	values := []ast.Expression{value}
+	if tuple, ok := value.(*ast.TupleLiteral); ok {
+		values = tuple.Elements
+	} else if len(returnTypes) > 1 && c.types[value] == unknown {
+		// a value of unknown type may be a tuple
+		return
+	}
+	if len(values) != len(returnTypes) {
+		c.errorf(token, "wrong number of return values for %s: want %d, got %d",
+			c.fn.name(), len(returnTypes), len(values))
+		return
+	}
+	for i, typ := range returnTypes {
+		if msg := c.typeError(c.types[values[i]], typ.Value); msg != "" {
+			c.errorf(token, "return value of %s: %s", c.fn.name(), msg)
+		}
+	}
+}
+
+// expression checks expr and returns its type.
+func (c *checker) expression(expr ast.Expression) Type {
+	t := c.inferType(expr)
+	c.types[expr] = t
+	return t
+}
+
+func (c *checker) inferType(expr ast.Expression) Type {
+	switch node := expr.(type) {
+	case *ast.Identifier:
+		return c.identifier(node)
+
+	case *ast.IntegerLiteral:
+		switch {
+		case node.Cast != nil:
+			return c.resolve(node.Cast.Value)
+		case node.Unsigned:
+			return Type(object.UINT64_OBJ)
+		}
+		return integerType
+
+	case *ast.FloatLiteral:
+		if node.Cast != nil {
+			return c.resolve(node.Cast.Value)
+		}
+		return floatType
+
+	case *ast.Boolean:
+		if node.Cast != nil {
+			return c.resolve(node.Cast.Value)
+		}
+		return booleanType
+
+	case *ast.CharLiteral:
+		return charType
+
+	case *ast.StringLiteral, *ast.FStringLiteral:
+		return stringType
+
+	case *ast.Null:
+		return nullType
+
+	case *ast.None:
+		return optionType
+
+	case *ast.PrefixExpression:
+		right := c.expression(node.Right)
+		switch node.Operator {
+		case "!", "not":
+			return booleanType
+		case "-", "~":
+			if right.isNumber() || c.namedType(right) != nil {
+				return right
+			}
+		}
+		return unknown
+
+	case *ast.InfixExpression:
+		left := c.expression(node.Left)
+		right := c.expression(node.Right)
+		if node.Operator == "&&" || node.Operator == "and" || node.Operator == "||" || node.Operator == "or" {
+			return unknown
+		}
+		t, mismatch := c.infixType(node.Operator, left, right)
+		if mismatch {
+			c.errorf(node.Token, "type mismatch: %s %s %s", left, node.Operator, right)
+		}
+		return t
+
+	case *ast.CastExpression:
+		val := c.expression(node.Left)
+		if msg := c.castError(val, node.Type.Value); msg != "" {
+			c.errorf(node.Token, "%s", msg)
+		}
+		return c.resolve(node.Type.Value)
+
+	case *ast.AssignExpression:
+		return c.assignExpression(node)
+
+	case *ast.IfExpression:
+		c.expression(node.Condition)
+		c.statements(node.Consequence.Statements)
+		if node.ConditionalAlternative != nil {
+			c.expression(node.ConditionalAlternative)
+		} else if node.Alternative != nil {
+			c.statements(node.Alternative.Statements)
+		}
+		return unknown
+
+	case *ast.MatchExpression:
+		c.expression(node.Condition)
+		for _, match := range node.MatchBlock.Cases {
+			match := match
+			c.block(match.Consequence, func() {
+				c.bindPattern(match.Pattern)
+				if match.Guard != nil {
+					c.expression(match.Guard)
+				}
+			})
+		}
+		if node.Alternative != nil {
+			c.statements(node.Alternative.Statements)
+		}
+		return unknown
+
+	case *ast.FunctionLiteral:
+		if node.Name != nil && c.scope.lookup(node.Name.Value) == nil {
+			c.scope.declare(node.Name.Value).function = node
+		}
+		c.function(node, unknown)
+		return unknown
+
+	case *ast.CallExpression:
+		return c.callExpression(node)
+
+	case *ast.NamedArgument:
+		return c.expression(node.Value)
+
+	case *ast.SpreadExpression:
+		c.expression(node.Value)
+		return unknown
+
+	case *ast.TryExpression:
+		c.expression(node.Value)
+		return unknown
+
+	case *ast.TupleLiteral:
+		for _, elem := range node.Elements {
+			c.expression(elem)
+		}
+		return unknown
+
+	case *ast.ArrayLiteral:
+		elem := unknown
+		for i, e := range node.Elements {
+			t := c.expression(e)
+			if i == 0 {
+				elem = t
+			} else if t != elem {
+				elem = unknown
+			}
+		}
+		return arrayOf(elem)
+
+	case *ast.HashLiteral:
+		for key, value := range node.Pairs {
+			c.expression(key)
+			c.expression(value)
+		}
+		return hashType
+
+	case *ast.IndexExpression:
+		left := c.expression(node.Left)
+		index := c.expression(node.Index)
+		if left.isArray() && index.isInteger() {
+			return left.elem()
+		}
+		return unknown
+
+	case *ast.FieldExpression:
+		return c.fieldExpression(node)
+
+	case *ast.StructLiteral:
+		return c.structLiteral(node)
+
+	case *ast.LoopStatement:
+		if node.WhileCondition != nil {
+			c.expression(node.WhileCondition)
+		}
+		if node.UntilCondition != nil {
+			c.expression(node.UntilCondition)
+		}
+		c.statements(node.Body.Statements)
+		return unknown
+
+	case *ast.ForStatement:
+		iterable := c.expression(node.Iterable)
+		c.block(node.Body, func() {
+			variable, _ := node.Variable.(*ast.Identifier)
+			value, _ := node.Value.(*ast.Identifier)
+			if variable != nil {
+				sym := c.scope.declare(variable.Value)
+				switch {
+				case value == nil && iterable.isArray():
+					sym.typ = iterable.elem()
+				case iterable.isArray():
+					sym.typ = integerType
+				}
+			}
+			if value != nil {
+				sym := c.scope.declare(value.Value)
+				if iterable.isArray() {
+					sym.typ = iterable.elem()
+				}
+			}
+		})
+		return unknown
+
+	case *ast.WithStatement:
+		c.expression(node.Resource)
+		c.block(node.Body, func() {
+			c.scope.declare(node.Name.Value)
+		})
+		return unknown
+	}
+	return unknown
+}
+
+func (c *checker) identifier(node *ast.Identifier) Type {
+	sym := c.scope.lookup(node.Value)
+	if sym == nil {
+		if !evaluator.IsBuiltin(node.Value) {
+			c.errorf(node.Token, "identifier not found: %s", node.Value)
+		}
+		return unknown
+	}
+	if sym.variant != nil && sym.variant.Fields == nil {
+		return sym.union
+	}
+	return sym.staticType()
+}
+
+// bindPattern binds the names captured by a match pattern in the current
+// scope, a name of a variant without fields matches that variant instead.
+func (c *checker) bindPattern(pattern ast.Pattern) {
+	switch pattern := pattern.(type) {
+	case *ast.BindingPattern:
+		if sym := c.scope.lookup(pattern.Name.Value); sym != nil && sym.variant != nil && sym.variant.Fields == nil {
+			return
+		}
+		c.scope.declare(pattern.Name.Value)
+	case *ast.VariantPattern:
+		for _, arg := range pattern.Arguments {
+			c.bindPattern(arg)
+		}
+	case *ast.ArrayPattern:
+		for _, elem := range pattern.Elements {
+			c.bindPattern(elem)
+		}
+		if pattern.Rest != nil {
+			c.bindPattern(pattern.Rest)
+		}
+	case *ast.HashPattern:
+		for _, value := range pattern.Values {
+			c.bindPattern(value)
+		}
+	case *ast.AlternativePattern:
+		for _, alternative := range pattern.Alternatives {
+			c.bindPattern(alternative)
+		}
+	}
+}
+
+func (c *checker) assignExpression(node *ast.AssignExpression) Type {
+	val := unknown
+	if node.Value != nil {
+		val = c.expression(node.Value)
+	}
+
+	current := c.expression(node.Target)
+	switch node.Operator {
+	case "=":
+	case "++", "--":
+		val = current
+	default:
+		operator := node.Operator[:len(node.Operator)-1]
+		t, mismatch := c.infixType(operator, current, val)
+		if mismatch {
+			c.errorf(node.Token, "type mismatch: %s %s %s", current, operator, val)
+			return current
+		}
+		val = t
+	}
+
+	switch target := node.Target.(type) {
+	case *ast.Identifier:
+		if msg := assignError(current, val); msg != "" {
+			c.errorf(node.Token, "%s", msg)
+		}
+	case *ast.IndexExpression:
+		// arrays are not typed by their elements at runtime, once one is
+		// replaced the element type inferred from the literal no longer holds
+		if id := ast.AssignedIdentifier(target); id != nil {
+			if sym := c.scope.lookup(id.Value); sym != nil && sym.staticType().isArray() {
+				sym.unstable = true
+			}
+		}
+	case *ast.FieldExpression:
+		definition := c.structType(c.types[target.Left])
+		if definition == nil {
+			break
+		}
+		for _, field := range definition.Fields {
+			if field.Name.Value != target.Field.Value {
+				continue
+			}
+			if msg := c.typeError(val, field.Type.Value); msg != "" {
+				c.errorf(node.Token, "field %s of %s: %s", field.Name.Value, definition.Name.Value, msg)
+			}
+		}
+	}
+	return current
+}
+
+// fieldExpression returns the type of a field of a struct, methods take
+// priority over fields.
+func (c *checker) fieldExpression(node *ast.FieldExpression) Type {
+	left := c.expression(node.Left)
+	if _, ok := c.methods[left][node.Field.Value]; ok {
+		return unknown
+	}
+	definition := c.structType(left)
+	if definition == nil {
+		return unknown
+	}
+	for _, field := range definition.Fields {
+		if field.Name.Value == node.Field.Value {
+			return c.resolve(field.Type.Value)
+		}
+	}
+	return unknown
+}
+
+func (c *checker) structLiteral(node *ast.StructLiteral) Type {
+	for _, field := range node.Fields {
+		c.expression(field.Value)
+	}
+
+	sym := c.scope.lookup(node.Name.Value)
+	if sym == nil {
+		c.errorf(node.Token, "identifier not found: %s", node.Name.Value)
+		return unknown
+	}
+	definition, ok := sym.definition.(*ast.StructStatement)
+	if !ok {
+		return unknown
+	}
+
+	given := make(map[string]bool)
+	for _, field := range node.Fields {
+		given[field.Name.Value] = true
+		var decl *ast.Parameter
+		for _, f := range definition.Fields {
+			if f.Name.Value == field.Name.Value {
+				decl = f
+			}
+		}
+		if decl == nil {
+			c.errorf(field.Name.Token, "unknown field %s in %s literal", field.Name.Value, definition.Name.Value)
+			continue
+		}
+		if msg := c.typeError(c.types[field.Value], decl.Type.Value); msg != "" {
+			c.errorf(field.Name.Token, "field %s of %s: %s", decl.Name.Value, definition.Name.Value, msg)
+		}
+	}
+	for _, decl := range definition.Fields {
+		if !given[decl.Name.Value] {
+			c.errorf(node.Token, "missing field %s in %s literal", decl.Name.Value, definition.Name.Value)
+		}
+	}
+	return Type(definition.Name.Value)
+}
+
+// callExpression checks the arguments of a call against the signature of the
+// function called when it is known, and returns the type of its result.
+func (c *checker) callExpression(node *ast.CallExpression) Type {
+	c.expression(node.Function)
+	for _, arg := range node.Parameters {
+		c.expression(arg)
+	}
+
+	switch fn := node.Function.(type) {
+	case *ast.Identifier:
+		sym := c.scope.lookup(fn.Value)
+		switch {
+		case sym == nil:
+			return builtinTypes[fn.Value]
+		case sym.variant != nil:
+			return sym.union
+		case sym.function != nil && !sym.unstable:
+			return c.checkCall(node, &function{literal: sym.function}, unknown)
+		}
+
+	case *ast.FieldExpression:
+		// a method called on its type is passed all the arguments, called
+		// on a value it is passed the value as self
+		if ident, ok := fn.Left.(*ast.Identifier); ok && c.definition(ident.Value) != nil {
+			owner := c.resolve(ident.Value)
+			if method, ok := c.methods[owner][fn.Field.Value]; ok {
+				return c.checkCall(node, &function{literal: method, owner: owner}, unknown)
+			}
+			return unknown
+		}
+		receiver := c.types[fn.Left]
+		if method, ok := c.methods[receiver][fn.Field.Value]; ok && receiver != unknown {
+			return c.checkCall(node, &function{literal: method, owner: receiver}, receiver)
+		}
+
+	case *ast.FunctionLiteral:
+		return c.checkCall(node, &function{literal: fn}, unknown)
+	}
+	return unknown
+}
+
+// checkCall checks the arguments of a call to fn, binding them to its
+// parameters like the evaluator does, and returns the type of its result.
+// A method called on a value is passed receiver before its arguments.
+func (c *checker) checkCall(node *ast.CallExpression, fn *function, receiver Type) Type {
+	var result Type
+	if returnTypes := fn.literal.ReturnTypes; len(returnTypes) == 1 {
+		result = c.resolve(returnTypes[0].Value)
+	}
+
+	var positional []Type
+	if receiver != unknown {
+		positional = append(positional, receiver)
+	}
+	var named []*ast.NamedArgument
+	for _, arg := range node.Parameters {
+		switch arg := arg.(type) {
+		case *ast.SpreadExpression:
+			// the number of arguments is only known at runtime
+			return result
+		case *ast.NamedArgument:
+			named = append(named, arg)
+		default:
+			positional = append(positional, c.types[arg])
+		}
+	}
+
+	fixed := fn.literal.Parameters
+	var variadic *ast.Parameter
+	if n := len(fixed); n != 0 && fixed[n-1].Variadic {
+		variadic = fixed[n-1]
+		fixed = fixed[:n-1]
+	}
+	if len(positional) > len(fixed) && variadic == nil {
+		c.errorf(node.Token, "wrong number of arguments for %s: want %s, got %d",
+			fn.name(), ast.Arity(fn.literal.Parameters), len(positional))
+		return result
+	}
+
+	values := make([]Type, len(fixed))
+	given := make([]bool, len(fixed))
+	for i := 0; i < len(positional) && i < len(fixed); i++ {
+		values[i], given[i] = positional[i], true
+	}
+	for _, arg := range named {
+		i := ast.ParameterIndex(fixed, arg.Name.Value)
+		if i < 0 {
+			c.errorf(node.Token, "unknown parameter %s for %s", arg.Name.Value, fn.name())
+			return result
+		}
+		if given[i] {
+			c.errorf(node.Token, "argument %s of %s given twice", arg.Name.Value, fn.name())
+			return result
+		}
+		values[i], given[i] = c.types[arg.Value], true
+	}
+
+	for i, param := range fixed {
+		if !given[i] {
+			if param.Default != nil {
+				continue
+			}
+			if len(named) == 0 {
+				c.errorf(node.Token, "wrong number of arguments for %s: want %s, got %d",
+					fn.name(), ast.Arity(fn.literal.Parameters), len(positional))
+			} else {
+				c.errorf(node.Token, "missing argument %s for %s", param.Name.Value, fn.name())
+			}
+			return result
+		}
+		c.checkArgument(node, fn, param, values[i])
+	}
+	if variadic != nil && len(positional) > len(fixed) {
+		for _, val := range positional[len(fixed):] {
+			c.checkArgument(node, fn, variadic, val)
+		}
+	}
+	return result
+}
+
+func (c *checker) checkArgument(node *ast.CallExpression, fn *function, param *ast.Parameter, val Type) {
+	if param.Type == nil {
+		return
+	}
+	if msg := c.typeError(val, param.Type.Value); msg != "" {
+		c.errorf(node.Token, "argument %s of %s: %s", param.Name.Value, fn.name(), msg)
+	}
+}
@@ -0,0 +1,123 @@
+package checker_test
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	"github.com/poolpOrg/julu/checker"
+	"github.com/poolpOrg/julu/lexer"
+	"github.com/poolpOrg/julu/parser"
+)
+
+func check(t *testing.T, input string) []string {
+	l := lexer.New(bufio.NewReader(strings.NewReader(input)))
+	p := parser.New(l)
+	program := p.Parse()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parser errors for %q: %v", input, p.Errors())
+	}
+	return checker.Check(program)
+}
+
+func TestCheckWellTyped(t *testing.T) {
+	tests := []string{
+		"let x = 1; let y = 2.5; x + y",
+		"let x: uint8 = 1; x + 300",
+		"let x: float = 1; x / 2",
+		"let mut x = 1; x += 2; x = 3",
+		"let mut x = null; x = 1; x = \"a\"",
+		"let s: string = null",
+		"fn f(x: int, y: float = 1.0) -> float => x + y; f(1); f(1, y: 2)",
+		"fn f(...xs: int) => xs; f(); f(1, 2, 3)",
+		"fn f(a, b) => a; let args = [1, 2]; f(...args)",
+		"fn even(n: int) -> bool { if n == 0 { return true } odd(n - 1) }\nfn odd(n: int) -> bool { if n == 0 { return false } even(n - 1) }",
+		"fn f() -> (int, string) { return 1, \"a\" }; let a, b = f(); b + \"!\"",
+		"fn f() { g() }; fn g() => 1",
+		"if true { let late = 1 }; late",
+		"let x = 1; let x = \"a\"; x + \"b\"",
+		"let f = fn(x) => x; f(1) + f(\"a\")",
+		"let mut f = fn(x: int) => x; f = fn(s: string) => s; f(\"a\")",
+		"for i, s in [\"a\", \"b\"] { i + 1; s + \"!\" }",
+		"let mut a = [1, 2]; a[0] = \"s\"; a[0] + \"t\"; for x in a { x + \"t\" }",
+		"match some(1) { case some(n) => n + 1 case none => 0 }",
+		"struct P { x: int }; P => { fn get(self) -> int => self.x }; let p = P{x: 1}; p.get() + P.get(p)",
+		"union Shape { Circle(r: float), Empty }; fn f(s: Shape) => s; f(Circle(r: 1.0)); f(Empty)",
+		"type UserID int; let u = 1 as UserID; u + (2 as UserID); (u as int) + 1",
+		"type Port = uint16; let p: Port = 80; p + 1",
+		"type B = int; type A = B; type B = A; let x: A = 1",
+		"len(\"abc\") + 1",
+		"'a' as int + 1",
+		"type Name string; let n = \"bob\" as Name; (n as string) + \"!\"",
+	}
+
+	for _, input := range tests {
+		if errors := check(t, input); len(errors) != 0 {
+			t.Errorf("unexpected errors for %q: %v", input, errors)
+		}
+	}
+}
+
+func TestCheckErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{input: "1 + \"a\"", expected: []string{"[1:3] type mismatch: INTEGER + STRING"}},
+		{input: "let x: uint8 = 1; let y: uint16 = 1; x + y", expected: []string{"[1:40] type mismatch: UINT8 + UINT16"}},
+		{input: "let x: int = 1.5", expected: []string{"[1:1] cannot use FLOAT as int"}},
+		{input: "let xs: [int] = [\"a\"]", expected: []string{"[1:1] cannot use STRING as int in element 0 of [int]"}},
+		{input: "let mut x = 1; x = \"s\"", expected: []string{"[1:18] cannot assign STRING to variable of type INTEGER"}},
+		{input: "let mut x = 1; x += 0.5", expected: []string{"[1:18] cannot assign FLOAT to variable of type INTEGER"}},
+		{input: "\"1\" as int", expected: []string{"[1:5] cannot convert STRING to int"}},
+		{input: "x + 1", expected: []string{"[1:1] identifier not found: x"}},
+		{input: "fn f(x: int) => x; f(\"a\")", expected: []string{"[1:21] argument x of f: cannot use STRING as int"}},
+		{input: "fn f(a, b) => a; f(1, 2, 3)", expected: []string{"[1:19] wrong number of arguments for f: want 2, got 3"}},
+		{input: "fn f(a, b) => a; f(b: 2)", expected: []string{"[1:19] missing argument a for f"}},
+		{input: "fn f(a, b) => a; f(1, c: 2)", expected: []string{"[1:19] unknown parameter c for f"}},
+		{input: "fn f(...xs: int) => xs; f(1, \"a\")", expected: []string{"[1:26] argument xs of f: cannot use STRING as int"}},
+		{input: "fn f() -> int { return \"a\" }", expected: []string{"[1:17] return value of f: cannot use STRING as int"}},
+		{input: "fn f() -> int => 1.5", expected: []string{"[1:18] return value of f: cannot use FLOAT as int"}},
+		{input: "fn f() -> (int, int) { return 1 }", expected: []string{"[1:24] wrong number of return values for f: want 2, got 1"}},
+		{input: "fn f() -> int { let x = \"a\"; x }", expected: []string{"[1:30] return value of f: cannot use STRING as int"}},
+		{input: "fn f() { 1 + \"a\" }", expected: []string{"[1:12] type mismatch: INTEGER + STRING"}},
+		{input: "for s in [\"a\"] { s + 1 }", expected: []string{"[1:20] type mismatch: STRING + INTEGER"}},
+		{input: "match 1 { case n if m => n }", expected: []string{"[1:21] identifier not found: m"}},
+		{input: "struct P { x: int }\nP{x: 1, y: 2}", expected: []string{"[2:9] unknown field y in P literal"}},
+		{input: "struct P { x: int, y: int }\nP{x: \"a\"}", expected: []string{
+			"[2:3] field x of P: cannot use STRING as int",
+			"[2:1] missing field y in P literal",
+		}},
+		{input: "struct P { x: int }\nlet mut p = P{x: 1}; p.x = true", expected: []string{"[2:26] field x of P: cannot use BOOLEAN as int"}},
+		{input: "struct P { x: int }\nlet p = P{x: 1}; p.x + \"a\"", expected: []string{"[2:22] type mismatch: INTEGER + STRING"}},
+		{input: "struct P { x: int }\nP => { fn add(self, n: int) -> int => self.x + n }\nlet p = P{x: 1}; p.add(\"a\")", expected: []string{
+			"[3:23] argument n of P.add: cannot use STRING as int",
+		}},
+		{input: "union Shape { Circle(r: float), Empty }\nfn f(s: Shape) => s; f(1)", expected: []string{"[2:23] argument s of f: cannot use INTEGER as Shape"}},
+		{input: "type UserID int\ntype OrderID int\nfn order(user: UserID, id: OrderID) => 1\norder(2 as OrderID, 1 as UserID)", expected: []string{
+			"[4:6] argument user of order: cannot use OrderID as UserID",
+			"[4:6] argument id of order: cannot use UserID as OrderID",
+		}},
+		{input: "type UserID int\nlet u = 1 as UserID; u + 1", expected: []string{"[2:24] type mismatch: UserID + INTEGER"}},
+		{input: "type UserID int\nlet u: UserID = 1", expected: []string{"[2:1] cannot use INTEGER as UserID"}},
+		{input: "type A = B\ntype B = A\nlet x: A = 1", expected: []string{
+			"[1:6] invalid recursive type: A",
+			"[2:6] invalid recursive type: B",
+		}},
+		{input: "type M N\ntype N = [M]\ntype C = M\n1 as C", expected: []string{
+			"[1:6] invalid recursive type: M",
+			"[2:6] invalid recursive type: N",
+		}},
+		{input: "let x = 1 + \"a\"; let y: string = 2", expected: []string{
+			"[1:11] type mismatch: INTEGER + STRING",
+			"[1:18] cannot use INTEGER as string",
+		}},
+	}
+
+	for _, tt := range tests {
+		errors := check(t, tt.input)
+		if strings.Join(errors, "\n") != strings.Join(tt.expected, "\n") {
+			t.Errorf("wrong errors for %q. got=%q, want=%q", tt.input, errors, tt.expected)
+		}
+	}
+}
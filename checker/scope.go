@@ -0,0 +1,66 @@
+package checker
+
+import "github.com/poolpOrg/julu/ast"
+
+// A scope holds the names bound by a function body, a match arm or the body
+// of a for or with statement. Like in the evaluator, the blocks of if and
+// while share the scope they appear in.
+type scope struct {
+	parent  *scope
+	symbols map[string]*symbol
+}
+
+// symbol is a name bound in a scope. It holds the static type of a variable,
+// the declaration of a named function, a type or a variant of a union.
+type symbol struct {
+	typ        Type
+	function   *ast.FunctionLiteral
+	definition ast.Statement
+	union      Type
+	variant    *ast.UnionVariant
+
+	// unstable is set for a name bound more than once in its scope or for
+	// an array one of whose elements is assigned, it has no static type
+	unstable bool
+}
+
+func newScope(parent *scope) *scope {
+	return &scope{parent: parent, symbols: make(map[string]*symbol)}
+}
+
+// declare binds name in the scope, marking it unstable if it already is.
+func (s *scope) declare(name string) *symbol {
+	if sym, ok := s.symbols[name]; ok {
+		sym.unstable = true
+		return sym
+	}
+	sym := &symbol{}
+	s.symbols[name] = sym
+	return sym
+}
+
+// local returns the symbol name is bound to in the scope itself, declaring
+// it if needed.
+func (s *scope) local(name string) *symbol {
+	if sym, ok := s.symbols[name]; ok {
+		return sym
+	}
+	return s.declare(name)
+}
+
+func (s *scope) lookup(name string) *symbol {
+	for ; s != nil; s = s.parent {
+		if sym, ok := s.symbols[name]; ok {
+			return sym
+		}
+	}
+	return nil
+}
+
+// staticType returns the type of the values the name is bound to.
+func (s *symbol) staticType() Type {
+	if s.unstable {
+		return unknown
+	}
+	return s.typ
+}
@@ -0,0 +1,303 @@
+package checker
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/poolpOrg/julu/ast"
+	"github.com/poolpOrg/julu/object"
+)
+
+// Type is the static type of an expression, named like the type of its
+// runtime values: INTEGER, STRING, the name of a struct... Arrays are
+// written [T] with T the type of their elements, or [] when it is unknown.
+// The zero value is a type the checker could not infer, it matches any type.
+type Type string
+
+const unknown Type = ""
+
+const (
+	integerType = Type(object.INTEGER_OBJ)
+	floatType   = Type(object.FLOAT_OBJ)
+	booleanType = Type(object.BOOLEAN_OBJ)
+	charType    = Type(object.CHAR_OBJ)
+	stringType  = Type(object.STRING_OBJ)
+	nullType    = Type(object.NULL_OBJ)
+	hashType    = Type(object.HASH_OBJ)
+	resultType  = Type(object.RESULT_OBJ)
+	optionType  = Type(object.OPTION_OBJ)
+)
+
+// builtinTypes holds the type returned by the builtins that always return
+// the same type.
+var builtinTypes = map[string]Type{
+	"len":  integerType,
+	"type": stringType,
+	"ok":   resultType,
+	"err":  resultType,
+	"some": optionType,
+	"any":  booleanType,
+	"all":  booleanType,
+}
+
+// declaredType returns the type of the values of the builtin type typeName.
+func declaredType(typeName string) (Type, bool) {
+	t, ok := object.DeclaredTypes[typeName]
+	return Type(t), ok
+}
+
+func (t Type) isNullable() bool {
+	return object.NullableTypes[object.ObjectType(t.String())]
+}
+
+func arrayOf(elem Type) Type {
+	return "[" + elem + "]"
+}
+
+func isArrayName(typeName string) bool {
+	return strings.HasPrefix(typeName, "[") && strings.HasSuffix(typeName, "]")
+}
+
+func (t Type) isArray() bool {
+	return isArrayName(string(t))
+}
+
+// elem returns the type of the elements of an array type.
+func (t Type) elem() Type {
+	return t[1 : len(t)-1]
+}
+
+func (t Type) isInteger() bool {
+	switch object.ObjectType(t) {
+	case object.INTEGER_OBJ, object.INT8_OBJ, object.INT16_OBJ, object.INT32_OBJ,
+		object.UINT8_OBJ, object.UINT16_OBJ, object.UINT32_OBJ, object.UINT64_OBJ:
+		return true
+	}
+	return false
+}
+
+func (t Type) isNumber() bool {
+	return t.isInteger() || t == floatType
+}
+
+// String returns the type as reported by the evaluator.
+func (t Type) String() string {
+	if t.isArray() {
+		return object.ARRAY_OBJ
+	}
+	return string(t)
+}
+
+// resolve returns the type of the values of the declared type typeName,
+// aliases are replaced by their target.
+func (c *checker) resolve(typeName string) Type {
+	if isArrayName(typeName) {
+		return arrayOf(c.resolve(typeName[1 : len(typeName)-1]))
+	}
+	if t, ok := declaredType(typeName); ok {
+		return t
+	}
+	switch definition := c.definition(typeName).(type) {
+	case *ast.TypeStatement:
+		if definition.Alias {
+			return c.resolve(definition.Type.Value)
+		}
+		return Type(definition.Name.Value)
+	case *ast.StructStatement, *ast.UnionStatement:
+		return Type(typeName)
+	}
+	return unknown
+}
+
+// definition returns the declaration of the type typeName, nil if it is not
+// a type declared by the program, if it is declared more than once or if it
+// is a type referring to itself.
+func (c *checker) definition(typeName string) ast.Statement {
+	sym := c.scope.lookup(typeName)
+	if sym == nil || sym.unstable {
+		return nil
+	}
+	if stmt, ok := sym.definition.(*ast.TypeStatement); ok && c.typeCycle(stmt) != nil {
+		return nil
+	}
+	return sym.definition
+}
+
+// typeCycle follows the types stmt refers to and returns the first type
+// declaration met twice, nil if they don't form a cycle.
+func (c *checker) typeCycle(stmt *ast.TypeStatement) *ast.TypeStatement {
+	seen := make(map[*ast.TypeStatement]bool)
+	for !seen[stmt] {
+		seen[stmt] = true
+		sym := c.scope.lookup(strings.Trim(stmt.Type.Value, "[]"))
+		if sym == nil || sym.unstable {
+			return nil
+		}
+		next, ok := sym.definition.(*ast.TypeStatement)
+		if !ok {
+			return nil
+		}
+		stmt = next
+	}
+	return stmt
+}
+
+// namedType returns the declaration of the distinct type t, if it is one.
+func (c *checker) namedType(t Type) *ast.TypeStatement {
+	definition, ok := c.definition(string(t)).(*ast.TypeStatement)
+	if !ok || definition.Alias {
+		return nil
+	}
+	return definition
+}
+
+// structType returns the declaration of the struct t, if it is one.
+func (c *checker) structType(t Type) *ast.StructStatement {
+	definition, _ := c.definition(string(t)).(*ast.StructStatement)
+	return definition
+}
+
+// typeError describes why a value of type val can't be used where a value of
+// the declared type typeName is expected, following the conversions of
+// typed declarations: integers convert to any numeric type and null is a
+// valid string, array or hash. It returns an empty string if it can.
+func (c *checker) typeError(val Type, typeName string) string {
+	for {
+		definition, ok := c.definition(typeName).(*ast.TypeStatement)
+		if !ok || !definition.Alias {
+			break
+		}
+		typeName = definition.Type.Value
+	}
+
+	if val == unknown {
+		return ""
+	}
+	if isArrayName(typeName) {
+		switch {
+		case val == nullType:
+			return ""
+		case !val.isArray():
+			return fmt.Sprintf("cannot use %s as %s", val, typeName)
+		}
+		if msg := c.typeError(val.elem(), typeName[1:len(typeName)-1]); msg != "" {
+			return fmt.Sprintf("%s in element 0 of %s", msg, typeName)
+		}
+		return ""
+	}
+
+	expected := c.resolve(typeName)
+	switch {
+	case expected == unknown:
+		return ""
+	case val.isInteger() && expected.isNumber():
+		return ""
+	case val == nullType && expected.isNullable():
+		return ""
+	case val.String() != expected.String():
+		return fmt.Sprintf("cannot use %s as %s", val, typeName)
+	}
+	return ""
+}
+
+// assignError describes why a value of type val can't be assigned to a
+// variable of type current, variables keep their type but integers convert
+// to the numeric type of the variable. It returns an empty string if it can.
+func assignError(current, val Type) string {
+	switch {
+	case current == unknown || val == unknown:
+		return ""
+	case val.String() == current.String():
+		return ""
+	case val.isInteger() && current.isNumber():
+		return ""
+	}
+	return fmt.Sprintf("cannot assign %s to variable of type %s", val, current)
+}
+
+// isComparison reports whether operator compares its operands.
+func isComparison(operator string) bool {
+	switch operator {
+	case "==", "!=", "<", ">", "<=", ">=", "is":
+		return true
+	}
+	return false
+}
+
+func isShift(operator string) bool {
+	switch operator {
+	case "<<", ">>", "<<<", ">>>", "**":
+		return true
+	}
+	return false
+}
+
+// infixType returns the type of operator applied to values of types left
+// and right, and whether the evaluator rejects them as a type mismatch. Like
+// the evaluator, an int combined with a fixed-width integer takes its type
+// and an integer combined with a float is promoted to float.
+func (c *checker) infixType(operator string, left, right Type) (Type, bool) {
+	result := func(t Type) Type {
+		if isComparison(operator) {
+			return booleanType
+		}
+		return t
+	}
+
+	switch {
+	case left == unknown || right == unknown || left == nullType || right == nullType:
+		return result(unknown), false
+	case left.isInteger() && right.isInteger():
+		switch {
+		case isShift(operator) || right == integerType:
+			return result(left), false
+		case left == integerType:
+			return result(right), false
+		case left != right:
+			return unknown, true
+		}
+		return result(left), false
+	case left.isNumber() && right.isNumber():
+		return result(floatType), false
+	case left.String() != right.String():
+		return unknown, true
+	case c.namedType(left) != nil:
+		return result(left), false
+	case left == stringType && operator == "+":
+		return stringType, false
+	case left == booleanType:
+		return booleanType, false
+	}
+	return result(unknown), false
+}
+
+// castError describes why a value of type val can't be converted to the
+// type typeName with as, it returns an empty string if it can. Values of a
+// distinct type convert like values of their underlying type.
+func (c *checker) castError(val Type, typeName string) string {
+	if named := c.namedType(val); named != nil {
+		val = c.resolve(named.Type.Value)
+	}
+	if val == unknown {
+		return ""
+	}
+
+	switch definition := c.definition(typeName).(type) {
+	case *ast.TypeStatement:
+		return c.castError(val, definition.Type.Value)
+	case *ast.StructStatement, *ast.UnionStatement:
+		return c.typeError(val, typeName)
+	}
+	if isArrayName(typeName) {
+		return c.typeError(val, typeName)
+	}
+
+	scalar := func(t Type) bool {
+		return t.isNumber() || t == charType || t == booleanType
+	}
+	expected, ok := declaredType(typeName)
+	if !ok || (scalar(val) && scalar(expected)) || val.String() == expected.String() {
+		return ""
+	}
+	return fmt.Sprintf("cannot convert %s to %s", val, typeName)
+}
@@ -8,6 +8,7 @@ import (
 	"io"
 	"os"
 
+	"github.com/poolpOrg/julu/checker"
 	"github.com/poolpOrg/julu/evaluator"
 	"github.com/poolpOrg/julu/lexer"
 	"github.com/poolpOrg/julu/object"
@@ -19,6 +20,7 @@ import (
 func main() {
 	var opt_mode string
 	flag.StringVar(&opt_mode, "mode", "", "mode to run the interpreter in")
+	flag.IntVar(&evaluator.MaxCallDepth, "max-call-depth", evaluator.MaxCallDepth, "maximum depth of nested function calls")
 	flag.Parse()
 
 	if term.IsTerminal(int(os.Stdin.Fd())) && flag.NArg() == 0 {
@@ -27,8 +29,10 @@ func main() {
 
 	var err error
 	var input io.Reader = os.Stdin
+	filename := "<stdin>"
 	if flag.NArg() != 0 {
-		input, err = os.Open(flag.Arg(0))
+		filename = flag.Arg(0)
+		input, err = os.Open(filename)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "could not open file: %s\n", err)
 			os.Exit(1)
@@ -55,13 +59,28 @@ func main() {
 		p := parser.New(l)
 		program := p.Parse()
 		if p.Errors() != nil {
-			printParserErrors(os.Stderr, p.Errors())
+			printErrors(os.Stderr, p.Errors())
 		}
 		fmt.Println(program.Inspect())
 		os.Exit(0)
 	}
 
-	env := object.NewEnvironment()
+	if opt_mode == "check" {
+		l := lexer.New(bufio.NewReader(input))
+		p := parser.New(l)
+		program := p.Parse()
+		if len(p.Errors()) > 0 {
+			printErrors(os.Stderr, p.Errors())
+			os.Exit(1)
+		}
+		if errors := checker.Check(program); len(errors) > 0 {
+			printErrors(os.Stderr, errors)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	env := object.NewFileEnvironment(filename)
 
 	code, err := io.ReadAll(input)
 	if err != nil {
@@ -76,36 +95,78 @@ func main() {
 		os.Exit(1)
 	}
 
-	if p.Errors() != nil {
-		printParserErrors(os.Stderr, p.Errors())
+	if len(p.Errors()) > 0 {
+		printErrors(os.Stderr, p.Errors())
+		os.Exit(1)
 	}
 
-	evaluated := evaluator.Eval(program, env)
-	if entryPoint, ok := env.Get("main"); ok {
-		evaluated = evaluator.EvalFunctionObject(entryPoint, env)
+	// ill-typed programs are refused before anything runs
+	if errors := checker.Check(program); len(errors) > 0 {
+		printErrors(os.Stderr, errors)
+		os.Exit(1)
 	}
 
-	if evaluated != nil {
-		if evaluated.Type() == object.VOID_OBJ {
-			os.Exit(0)
+	evaluated := evaluator.Eval(program, env)
+	if entryPoint, ok := env.Get("main"); ok && !isError(evaluated) {
+		// arguments following the script name are passed to main
+		var args []string
+		if flag.NArg() > 1 {
+			args = flag.Args()[1:]
 		}
-		if evaluated.Type() == object.INTEGER_OBJ {
-			os.Exit(int(evaluated.(*object.Integer).Value))
+		evaluated = evaluator.EvalMain(entryPoint, args)
+	}
+
+	os.Exit(exitCode(evaluated))
+}
+
+func isError(obj object.Object) bool {
+	return obj != nil && obj.Type() == object.ERROR_OBJ
+}
+
+// exitCode maps the value returned by main, or by the program when it has no
+// main, to the exit status of the process: integers from 0 to 255 are used as
+// is, true is success and false failure, runtime errors are reported with a
+// traceback and fail, and so do err results while ok results map their value.
+func exitCode(evaluated object.Object) int {
+	switch evaluated := evaluated.(type) {
+	case *object.Integer:
+		return exitStatus(evaluated, evaluated.Value)
+	case *object.SizedInteger:
+		if !evaluated.Signed && evaluated.Value > 255 {
+			return exitStatus(evaluated, -1)
 		}
-		if evaluated.Type() == object.BOOLEAN_OBJ {
-			if evaluated.(*object.Boolean).Value {
-				os.Exit(0)
-			}
-			os.Exit(1)
+		return exitStatus(evaluated, evaluated.Int64())
+	case *object.Boolean:
+		if evaluated.Value {
+			return 0
 		}
-		if evaluated.Type() == object.ERROR_OBJ {
-			fmt.Fprintf(os.Stderr, "error: %s\n", evaluated.Inspect())
-			os.Exit(1)
+		return 1
+	case *object.Error:
+		fmt.Fprint(os.Stderr, evaluated.Traceback())
+		return 1
+	case *object.Result:
+		if !evaluated.Ok {
+			fmt.Fprintf(os.Stderr, "error: %s\n", evaluated.Value.Inspect())
+			return 1
 		}
+		return exitCode(evaluated.Value)
+	default:
+		return 0
+	}
+}
+
+// exitStatus returns n, the value of the integer returned, as exit status.
+// The system would truncate a status out of the 0 to 255 range, so it fails
+// instead.
+func exitStatus(returned object.Object, n int64) int {
+	if n < 0 || n > 255 {
+		fmt.Fprintf(os.Stderr, "error: exit status %s out of range\n", returned.Inspect())
+		return 1
 	}
+	return int(n)
 }
 
-func printParserErrors(out io.Writer, errors []string) {
+func printErrors(out io.Writer, errors []string) {
 	for _, msg := range errors {
 		fmt.Fprintf(out, "\t%s\n", msg)
 	}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/poolpOrg/julu/object"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		value    object.Object
+		expected int
+	}{
+		{value: &object.Integer{Value: 0}, expected: 0},
+		{value: &object.Integer{Value: 3}, expected: 3},
+		{value: &object.Integer{Value: 255}, expected: 255},
+		{value: &object.Integer{Value: 256}, expected: 1},
+		{value: &object.Integer{Value: -1}, expected: 1},
+		{value: object.NewSizedInteger(200, 8, false), expected: 200},
+		{value: object.NewSizedInteger(256, 16, false), expected: 1},
+		{value: object.NewSizedInteger(1<<63, 64, false), expected: 1},
+		{value: object.NewSizedInteger(0xff, 8, true), expected: 1},
+		{value: &object.Boolean{Value: true}, expected: 0},
+		{value: &object.Boolean{Value: false}, expected: 1},
+		{value: &object.Result{Ok: true, Value: &object.Integer{Value: 300}}, expected: 1},
+	}
+
+	for _, tt := range tests {
+		if code := exitCode(tt.value); code != tt.expected {
+			t.Errorf("wrong exit code for %s. got=%d, want=%d", tt.value.Inspect(), code, tt.expected)
+		}
+	}
+}
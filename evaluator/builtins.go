@@ -1,7 +1,12 @@
 package evaluator
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/poolpOrg/julu/object"
@@ -9,30 +14,118 @@ import (
 
 var builtins = map[string]*object.Builtin{
 	"type": {
-		Fn: builtin_type,
+		Parameters: []string{"value"},
+		Fn:         builtin_type,
 	},
 
 	"len": {
-		Fn: builtin_len,
+		Parameters: []string{"value"},
+		Fn:         builtin_len,
 	},
 	"println": {
 		Fn: builtin_println,
 	},
+	"range": {
+		Fn: builtin_range,
+	},
+	"getenv": {
+		Parameters: []string{"name"},
+		Fn:         builtin_getenv,
+	},
+	"environ": {
+		Parameters: []string{},
+		Fn:         builtin_environ,
+	},
+	"ok": {
+		Parameters: []string{"value"},
+		Fn:         builtin_ok,
+	},
+	"err": {
+		Parameters: []string{"error"},
+		Fn:         builtin_err,
+	},
+	"some": {
+		Parameters: []string{"value"},
+		Fn:         builtin_some,
+	},
+	"read_file": {
+		Parameters: []string{"path"},
+		Fn:         builtin_read_file,
+	},
+	"open": {
+		Parameters: []string{"path", "mode"},
+		Fn:         builtin_open,
+	},
+	"read": {
+		Parameters: []string{"file"},
+		Fn:         builtin_read,
+	},
+	"write": {
+		Parameters: []string{"file", "s"},
+		Fn:         builtin_write,
+	},
+	"close": {
+		Parameters: []string{"file"},
+		Fn:         builtin_close,
+	},
+	"parse_int": {
+		Parameters: []string{"s"},
+		Fn:         builtin_parse_int,
+	},
+	"parse_float": {
+		Parameters: []string{"s"},
+		Fn:         builtin_parse_float,
+	},
+	"map": {
+		Parameters: []string{"iterable", "fn"},
+		Fn:         builtin_map,
+	},
+	"filter": {
+		Parameters: []string{"iterable", "fn"},
+		Fn:         builtin_filter,
+	},
+	"reduce": {
+		Parameters: []string{"iterable", "fn", "initial"},
+		Fn:         builtin_reduce,
+	},
+	"sort": {
+		Parameters: []string{"iterable", "less"},
+		Fn:         builtin_sort,
+	},
+	"any": {
+		Parameters: []string{"iterable", "fn"},
+		Fn:         builtin_any,
+	},
+	"all": {
+		Parameters: []string{"iterable", "fn"},
+		Fn:         builtin_all,
+	},
+	"each": {
+		Parameters: []string{"iterable", "fn"},
+		Fn:         builtin_each,
+	},
 
 	// TEMPORARY: This is a temporary function to test the evaluator.
 	"sleep": {
-		Fn: builtin_sleep,
+		Parameters: []string{"seconds"},
+		Fn:         builtin_sleep,
 	},
 }
 
-func builtin_type(args ...object.Object) object.Object {
+func init() {
+	for name, builtin := range builtins {
+		builtin.Name = name
+	}
+}
+
+func builtin_type(ctx object.Context, args ...object.Object) object.Object {
 	if len(args) != 1 {
 		return &object.Error{Message: fmt.Sprintf("wrong number of arguments. got=%d, want=1", len(args))}
 	}
 	return &object.String{Value: string(args[0].Type())}
 }
 
-func builtin_len(args ...object.Object) object.Object {
+func builtin_len(ctx object.Context, args ...object.Object) object.Object {
 	if len(args) != 1 {
 		return &object.Error{Message: fmt.Sprintf("wrong number of arguments. got=%d, want=1", len(args))}
 	}
@@ -47,16 +140,430 @@ func builtin_len(args ...object.Object) object.Object {
 	}
 }
 
-func builtin_println(args ...object.Object) object.Object {
+func builtin_println(ctx object.Context, args ...object.Object) object.Object {
 	for _, arg := range args {
 		fmt.Println(arg.Inspect())
 	}
 	return nil
 }
 
+func builtin_range(ctx object.Context, args ...object.Object) object.Object {
+	if len(args) < 1 || len(args) > 3 {
+		return &object.Error{Message: fmt.Sprintf("wrong number of arguments. got=%d, want=1..3", len(args))}
+	}
+
+	bounds := make([]int64, 0, len(args))
+	for _, arg := range args {
+		bound, ok := integerValue(arg)
+		if !ok {
+			return &object.Error{Message: fmt.Sprintf("argument to `range` must be INTEGER, got %s", arg.Type())}
+		}
+		bounds = append(bounds, bound)
+	}
+
+	switch len(bounds) {
+	case 1:
+		return &object.Range{Start: 0, End: bounds[0], Step: 1}
+	case 2:
+		return &object.Range{Start: bounds[0], End: bounds[1], Step: 1}
+	default:
+		if bounds[2] == 0 {
+			return &object.Error{Message: "argument to `range` must have a non-zero step"}
+		}
+		return &object.Range{Start: bounds[0], End: bounds[1], Step: bounds[2]}
+	}
+}
+
+func builtin_getenv(ctx object.Context, args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return &object.Error{Message: fmt.Sprintf("wrong number of arguments. got=%d, want=1", len(args))}
+	}
+
+	name, ok := args[0].(*object.String)
+	if !ok {
+		return &object.Error{Message: fmt.Sprintf("argument to `getenv` must be STRING, got %s", args[0].Type())}
+	}
+
+	value, ok := os.LookupEnv(name.Value)
+	if !ok {
+		return NULL
+	}
+	return &object.String{Value: value}
+}
+
+func builtin_environ(ctx object.Context, args ...object.Object) object.Object {
+	if len(args) != 0 {
+		return &object.Error{Message: fmt.Sprintf("wrong number of arguments. got=%d, want=0", len(args))}
+	}
+
+	pairs := make(map[object.HashKey]object.HashPair)
+	for _, variable := range os.Environ() {
+		name, value, _ := strings.Cut(variable, "=")
+		key := &object.String{Value: name}
+		pairs[key.HashKey()] = object.HashPair{Key: key, Value: &object.String{Value: value}}
+	}
+	return &object.Hash{Pairs: pairs}
+}
+
+func builtin_ok(ctx object.Context, args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return &object.Error{Message: fmt.Sprintf("wrong number of arguments. got=%d, want=1", len(args))}
+	}
+	return &object.Result{Ok: true, Value: args[0]}
+}
+
+func builtin_err(ctx object.Context, args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return &object.Error{Message: fmt.Sprintf("wrong number of arguments. got=%d, want=1", len(args))}
+	}
+	return &object.Result{Ok: false, Value: args[0]}
+}
+
+func builtin_some(ctx object.Context, args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return &object.Error{Message: fmt.Sprintf("wrong number of arguments. got=%d, want=1", len(args))}
+	}
+	return &object.Option{Value: args[0]}
+}
+
+// builtin_read_file returns the content of a file as ok(string), or the
+// reason it could not be read as err(string).
+func builtin_read_file(ctx object.Context, args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return &object.Error{Message: fmt.Sprintf("wrong number of arguments. got=%d, want=1", len(args))}
+	}
+
+	path, ok := args[0].(*object.String)
+	if !ok {
+		return &object.Error{Message: fmt.Sprintf("argument to `read_file` must be STRING, got %s", args[0].Type())}
+	}
+
+	content, err := os.ReadFile(path.Value)
+	if err != nil {
+		return &object.Result{Ok: false, Value: &object.String{Value: err.Error()}}
+	}
+	return &object.Result{Ok: true, Value: &object.String{Value: string(content)}}
+}
+
+// fileModes maps the modes accepted by open to the flags of os.OpenFile.
+var fileModes = map[string]int{
+	"r":  os.O_RDONLY,
+	"w":  os.O_WRONLY | os.O_CREATE | os.O_TRUNC,
+	"a":  os.O_WRONLY | os.O_CREATE | os.O_APPEND,
+	"r+": os.O_RDWR,
+	"w+": os.O_RDWR | os.O_CREATE | os.O_TRUNC,
+	"a+": os.O_RDWR | os.O_CREATE | os.O_APPEND,
+}
+
+// builtin_open opens a file for reading, or in the mode given as second
+// argument, returning ok(file) or err(string).
+func builtin_open(ctx object.Context, args ...object.Object) object.Object {
+	if len(args) < 1 || len(args) > 2 {
+		return &object.Error{Message: fmt.Sprintf("wrong number of arguments. got=%d, want=1..2", len(args))}
+	}
+
+	path, ok := args[0].(*object.String)
+	if !ok {
+		return &object.Error{Message: fmt.Sprintf("argument to `open` must be STRING, got %s", args[0].Type())}
+	}
+	flag := os.O_RDONLY
+	if len(args) == 2 {
+		mode, ok := args[1].(*object.String)
+		if !ok {
+			return &object.Error{Message: fmt.Sprintf("argument to `open` must be STRING, got %s", args[1].Type())}
+		}
+		if flag, ok = fileModes[mode.Value]; !ok {
+			return &object.Error{Message: fmt.Sprintf("invalid file mode: %q", mode.Value)}
+		}
+	}
+
+	f, err := os.OpenFile(path.Value, flag, 0666)
+	if err != nil {
+		return &object.Result{Ok: false, Value: &object.String{Value: err.Error()}}
+	}
+	return &object.Result{Ok: true, Value: &object.File{Name: path.Value, File: f}}
+}
+
+func fileArgument(name string, args []object.Object, want int) (*object.File, object.Object) {
+	if len(args) != want {
+		return nil, &object.Error{Message: fmt.Sprintf("wrong number of arguments. got=%d, want=%d", len(args), want)}
+	}
+	f, ok := args[0].(*object.File)
+	if !ok {
+		return nil, &object.Error{Message: fmt.Sprintf("argument to `%s` must be FILE, got %s", name, args[0].Type())}
+	}
+	return f, nil
+}
+
+// builtin_read reads a file to its end, returning ok(string) or err(string).
+func builtin_read(ctx object.Context, args ...object.Object) object.Object {
+	f, err := fileArgument("read", args, 1)
+	if err != nil {
+		return err
+	}
+
+	content, rerr := f.ReadAll()
+	if rerr != nil {
+		return &object.Result{Ok: false, Value: &object.String{Value: rerr.Error()}}
+	}
+	return &object.Result{Ok: true, Value: &object.String{Value: content}}
+}
+
+// builtin_write writes a string to a file, returning ok(int), the number of
+// bytes written, or err(string).
+func builtin_write(ctx object.Context, args ...object.Object) object.Object {
+	f, err := fileArgument("write", args, 2)
+	if err != nil {
+		return err
+	}
+	s, ok := args[1].(*object.String)
+	if !ok {
+		return &object.Error{Message: fmt.Sprintf("argument to `write` must be STRING, got %s", args[1].Type())}
+	}
+
+	n, werr := f.Write(s.Value)
+	if werr != nil {
+		return &object.Result{Ok: false, Value: &object.String{Value: werr.Error()}}
+	}
+	return &object.Result{Ok: true, Value: &object.Integer{Value: int64(n)}}
+}
+
+// builtin_close closes a file, returning ok(null) or err(string).
+func builtin_close(ctx object.Context, args ...object.Object) object.Object {
+	f, err := fileArgument("close", args, 1)
+	if err != nil {
+		return err
+	}
+
+	if cerr := f.Close(); cerr != nil {
+		return &object.Result{Ok: false, Value: &object.String{Value: cerr.Error()}}
+	}
+	return &object.Result{Ok: true, Value: NULL}
+}
+
+// builtin_parse_int parses an integer written like an integer literal,
+// returning ok(int) or err(string).
+func builtin_parse_int(ctx object.Context, args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return &object.Error{Message: fmt.Sprintf("wrong number of arguments. got=%d, want=1", len(args))}
+	}
+
+	s, ok := args[0].(*object.String)
+	if !ok {
+		return &object.Error{Message: fmt.Sprintf("argument to `parse_int` must be STRING, got %s", args[0].Type())}
+	}
+
+	value, err := strconv.ParseInt(strings.TrimSpace(s.Value), 0, 64)
+	if errors.Is(err, strconv.ErrRange) {
+		return &object.Result{Ok: false, Value: &object.String{Value: fmt.Sprintf("integer out of range: %q", s.Value)}}
+	} else if err != nil {
+		return &object.Result{Ok: false, Value: &object.String{Value: fmt.Sprintf("invalid integer: %q", s.Value)}}
+	}
+	return &object.Result{Ok: true, Value: &object.Integer{Value: value}}
+}
+
+// builtin_parse_float parses a float, returning ok(float) or err(string).
+func builtin_parse_float(ctx object.Context, args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return &object.Error{Message: fmt.Sprintf("wrong number of arguments. got=%d, want=1", len(args))}
+	}
+
+	s, ok := args[0].(*object.String)
+	if !ok {
+		return &object.Error{Message: fmt.Sprintf("argument to `parse_float` must be STRING, got %s", args[0].Type())}
+	}
+
+	value, err := strconv.ParseFloat(strings.TrimSpace(s.Value), 64)
+	if err != nil {
+		return &object.Result{Ok: false, Value: &object.String{Value: fmt.Sprintf("invalid float: %q", s.Value)}}
+	}
+	return &object.Result{Ok: true, Value: &object.Float{Value: value}}
+}
+
+// callbackArguments checks the arguments of the builtin name which takes
+// an iterable and a function, followed by max-2 other arguments. The
+// function may be left out if min is 1. It returns the elements of the
+// iterable, the values for a hash, and the function.
+func callbackArguments(name string, args []object.Object, min int, max int) ([]object.Object, object.Object, object.Object) {
+	if len(args) < min || len(args) > max {
+		want := fmt.Sprint(max)
+		if min != max {
+			want = fmt.Sprintf("%d..%d", min, max)
+		}
+		return nil, nil, &object.Error{Message: fmt.Sprintf("wrong number of arguments. got=%d, want=%s", len(args), want)}
+	}
+
+	switch args[0].(type) {
+	case *object.Array, *object.String, *object.Hash, *object.Range:
+	default:
+		return nil, nil, &object.Error{Message: fmt.Sprintf("argument to `%s` must be iterable, got %s", name, args[0].Type())}
+	}
+	elements := []object.Object{}
+	iterate(args[0], func(key, elem object.Object) object.Object {
+		elements = append(elements, elem)
+		return nil
+	})
+
+	if len(args) < 2 {
+		return elements, nil, nil
+	}
+	switch args[1].(type) {
+	case *object.Function, *object.Builtin, *object.BoundMethod:
+		return elements, args[1], nil
+	}
+	return nil, nil, &object.Error{Message: fmt.Sprintf("argument to `%s` must be FUNCTION, got %s", name, args[1].Type())}
+}
+
+func builtin_map(ctx object.Context, args ...object.Object) object.Object {
+	elements, fn, err := callbackArguments("map", args, 2, 2)
+	if err != nil {
+		return err
+	}
+
+	result := make([]object.Object, len(elements))
+	for i, elem := range elements {
+		result[i] = ctx.Call(fn, elem)
+		if isError(result[i]) {
+			return result[i]
+		}
+	}
+	return &object.Array{Elements: result}
+}
+
+func builtin_filter(ctx object.Context, args ...object.Object) object.Object {
+	elements, fn, err := callbackArguments("filter", args, 2, 2)
+	if err != nil {
+		return err
+	}
+
+	result := []object.Object{}
+	for _, elem := range elements {
+		keep := ctx.Call(fn, elem)
+		if isError(keep) {
+			return keep
+		}
+		if isTruthy(keep) {
+			result = append(result, elem)
+		}
+	}
+	return &object.Array{Elements: result}
+}
+
+func builtin_reduce(ctx object.Context, args ...object.Object) object.Object {
+	elements, fn, err := callbackArguments("reduce", args, 2, 3)
+	if err != nil {
+		return err
+	}
+
+	// without an initial value the first element is used
+	var acc object.Object
+	if len(args) == 3 {
+		acc = args[2]
+	} else if len(elements) != 0 {
+		acc, elements = elements[0], elements[1:]
+	} else {
+		return &object.Error{Message: "reduce of an empty iterable with no initial value"}
+	}
+
+	for _, elem := range elements {
+		acc = ctx.Call(fn, acc, elem)
+		if isError(acc) {
+			return acc
+		}
+	}
+	return acc
+}
+
+// builtin_sort returns the elements sorted in increasing order, or by the
+// less function which reports whether its first argument comes before the
+// second one. The sort is stable.
+func builtin_sort(ctx object.Context, args ...object.Object) object.Object {
+	elements, less, err := callbackArguments("sort", args, 1, 2)
+	if err != nil {
+		return err
+	}
+
+	// the first error stops the comparisons and is returned once sorted
+	var failure object.Object
+	sort.SliceStable(elements, func(i, j int) bool {
+		if failure != nil {
+			return false
+		}
+		var before object.Object
+		if less != nil {
+			before = ctx.Call(less, elements[i], elements[j])
+		} else {
+			before = evalInfixExpression("<", elements[i], elements[j])
+		}
+		if isError(before) {
+			failure = before
+			return false
+		}
+		return isTruthy(before)
+	})
+	if failure != nil {
+		return failure
+	}
+	return &object.Array{Elements: elements}
+}
+
+func builtin_any(ctx object.Context, args ...object.Object) object.Object {
+	elements, fn, err := callbackArguments("any", args, 1, 2)
+	if err != nil {
+		return err
+	}
+
+	for _, elem := range elements {
+		if fn != nil {
+			elem = ctx.Call(fn, elem)
+			if isError(elem) {
+				return elem
+			}
+		}
+		if isTruthy(elem) {
+			return TRUE
+		}
+	}
+	return FALSE
+}
+
+func builtin_all(ctx object.Context, args ...object.Object) object.Object {
+	elements, fn, err := callbackArguments("all", args, 1, 2)
+	if err != nil {
+		return err
+	}
+
+	for _, elem := range elements {
+		if fn != nil {
+			elem = ctx.Call(fn, elem)
+			if isError(elem) {
+				return elem
+			}
+		}
+		if !isTruthy(elem) {
+			return FALSE
+		}
+	}
+	return TRUE
+}
+
+func builtin_each(ctx object.Context, args ...object.Object) object.Object {
+	elements, fn, err := callbackArguments("each", args, 2, 2)
+	if err != nil {
+		return err
+	}
+
+	for _, elem := range elements {
+		if result := ctx.Call(fn, elem); isError(result) {
+			return result
+		}
+	}
+	return nil
+}
+
 // TEMPORARY: This is a temporary function to test the evaluator.
 
-func builtin_sleep(args ...object.Object) object.Object {
+func builtin_sleep(ctx object.Context, args ...object.Object) object.Object {
 	if len(args) != 1 {
 		return &object.Error{Message: fmt.Sprintf("wrong number of arguments. got=%d, want=1", len(args))}
 	}
@@ -68,3 +575,10 @@ func builtin_sleep(args ...object.Object) object.Object {
 	time.Sleep(time.Duration(args[0].(*object.Integer).Value) * time.Second)
 	return nil
 }
+
+// IsBuiltin reports whether name refers to a builtin when the program does
+// not bind it.
+func IsBuiltin(name string) bool {
+	_, ok := builtins[name]
+	return ok
+}
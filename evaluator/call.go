@@ -0,0 +1,458 @@
+package evaluator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/poolpOrg/julu/ast"
+	"github.com/poolpOrg/julu/lexer"
+	"github.com/poolpOrg/julu/object"
+)
+
+type namedArgument struct {
+	name  string
+	value object.Object
+}
+
+func evalCallExpression(node *ast.CallExpression, env *object.Environment) object.Object {
+	fn := Eval(node.Function, env)
+	if isError(fn) {
+		return fn
+	}
+	args, named, err := evalArguments(node.Parameters, env)
+	if err != nil {
+		return err
+	}
+	return locate(callFunction(env.Frame(), node, fn, args, named), node.Token, env)
+}
+
+// evalArguments evaluates the arguments of a call, expanding spread arrays
+// into positional arguments.
+func evalArguments(exps []ast.Expression, env *object.Environment) ([]object.Object, []namedArgument, object.Object) {
+	var args []object.Object
+	var named []namedArgument
+
+	for _, e := range exps {
+		switch e := e.(type) {
+		case *ast.SpreadExpression:
+			val := Eval(e.Value, env)
+			if isError(val) {
+				return nil, nil, val
+			}
+			switch val := val.(type) {
+			case *object.Array:
+				args = append(args, val.Elements...)
+			case *object.Tuple:
+				args = append(args, val.Elements...)
+			default:
+				return nil, nil, newErrorAt(e.Token, "cannot spread %s into arguments", val.Type())
+			}
+
+		case *ast.NamedArgument:
+			val := Eval(e.Value, env)
+			if isError(val) {
+				return nil, nil, val
+			}
+			named = append(named, namedArgument{name: e.Name.Value, value: val})
+
+		default:
+			val := Eval(e, env)
+			if isError(val) {
+				return nil, nil, val
+			}
+			args = append(args, val)
+		}
+	}
+	return args, named, nil
+}
+
+func applyFunction(fn object.Object, args []object.Object) object.Object {
+	return callFunction(nil, nil, fn, args, nil)
+}
+
+// builtinContext lets a builtin call functions on behalf of its caller.
+type builtinContext struct {
+	caller *object.Frame
+	node   *ast.CallExpression
+}
+
+func (c *builtinContext) Call(fn object.Object, args ...object.Object) object.Object {
+	return callFunction(c.caller, c.node, fn, args, nil)
+}
+
+// tailCall is a call in tail position, it is returned to the callFunction
+// running the current call which performs it in place of recursing, so that
+// tail recursion runs in constant stack space.
+type tailCall struct {
+	node  *ast.CallExpression
+	fn    object.Object
+	args  []object.Object
+	named []namedArgument
+}
+
+func (t *tailCall) Type() object.ObjectType { return TAIL_CALL_OBJ }
+func (t *tailCall) Inspect() string         { return "tail call" }
+
+func evalTailCall(node *ast.CallExpression, env *object.Environment) object.Object {
+	fn := Eval(node.Function, env)
+	if isError(fn) {
+		return fn
+	}
+	args, named, err := evalArguments(node.Parameters, env)
+	if err != nil {
+		return err
+	}
+	switch fn.(type) {
+	case *object.Function, *object.BoundMethod:
+		return &tailCall{node: node, fn: fn, args: args, named: named}
+	}
+	// builtins don't nest, calling them right away keeps the caller in the
+	// call stack of the functions they call back
+	return locate(callFunction(env.Frame(), node, fn, args, named), node.Token, env)
+}
+
+// evalTailBlock evaluates a block in tail position, such as the body of a
+// function, its last expression is in tail position too.
+func evalTailBlock(block *ast.BlockStatement, env *object.Environment) object.Object {
+	var result object.Object
+	var defers deferredCalls
+
+	for i, statement := range block.Statements {
+		if stmt, ok := statement.(*ast.DeferStatement); ok {
+			if result = defers.add(stmt, env); result != nil {
+				break
+			}
+			continue
+		}
+		if stmt, ok := statement.(*ast.ExpressionStatement); ok && i == len(block.Statements)-1 {
+			result = evalTailExpression(stmt.Expression, env)
+			break
+		}
+		result = Eval(statement, env)
+		if result != nil && isControlFlow(result) {
+			break
+		}
+	}
+	return defers.run(result, env)
+}
+
+// evalTailExpression evaluates an expression in tail position, a call is
+// returned as a tail call and the branches of an if are in tail position.
+func evalTailExpression(expr ast.Expression, env *object.Environment) object.Object {
+	switch expr := expr.(type) {
+	case *ast.CallExpression:
+		return evalTailCall(expr, env)
+
+	case *ast.IfExpression:
+		condition := Eval(expr.Condition, env)
+		if isError(condition) {
+			return condition
+		}
+		if isTruthy(condition) {
+			return evalTailBlock(expr.Consequence, env)
+		}
+		if expr.ConditionalAlternative != nil {
+			return evalTailExpression(expr.ConditionalAlternative, env)
+		} else if expr.Alternative != nil {
+			return evalTailBlock(expr.Alternative, env)
+		}
+		return NULL
+	}
+	return Eval(expr, env)
+}
+
+// callFunction calls fn with args on behalf of the caller frame, nil for
+// calls made from the top level or by the interpreter. Mismatches between
+// the arguments or the returned value and the signature of fn are reported
+// at the position of the call node, when there is one.
+//
+// Tail calls made by fn replace its frame and are performed here, the
+// return signatures of the functions they went through are checked once
+// the last one has returned.
+func callFunction(caller *object.Frame, node *ast.CallExpression, fn object.Object, args []object.Object, named []namedArgument) object.Object {
+	type pendingCheck struct {
+		node *ast.CallExpression
+		fn   *object.Function
+	}
+	var checks []pendingCheck
+
+	for {
+		var result object.Object
+
+		switch f := fn.(type) {
+		case *object.BoundMethod:
+			fn, args = f.Method, append([]object.Object{f.Receiver}, args...)
+			continue
+
+		case *object.Function:
+			frame := &object.Frame{Function: functionName(f), Call: node, Caller: caller, Depth: 1}
+			if caller != nil {
+				frame.Depth = caller.Depth + 1
+			}
+			if frame.Depth > MaxCallDepth {
+				return stackOverflow(frame)
+			}
+
+			extendedEnv, err := extendFunctionEnv(node, f, args, named, frame)
+			if err != nil {
+				return err
+			}
+			result = unwrapReturnValue(evalTailBlock(f.Body, extendedEnv))
+			switch result.(type) {
+			case *object.Break, *object.Continue:
+				// a function can't interrupt the loops of its caller
+				result = callError(node, "%s outside a loop in %s", result.Inspect(), functionName(f))
+			}
+			if err, ok := result.(*object.Error); ok {
+				// the error was raised by this call, or by a builtin it
+				// called, if no nested call claimed it
+				if err.Stack == nil {
+					err.Stack = frame
+				}
+				return err
+			}
+			// a function tail calling itself only needs to be checked once
+			if len(f.ReturnTypes) != 0 && (len(checks) == 0 || checks[len(checks)-1].fn != f) {
+				checks = append(checks, pendingCheck{node: node, fn: f})
+			}
+
+		case *object.Builtin:
+			args, err := builtinArguments(node, f, args, named)
+			if err != nil {
+				return err
+			}
+			result = f.Fn(&builtinContext{caller: caller, node: node}, args...)
+			if isError(result) {
+				return result
+			}
+
+		default:
+			return newError("not a function: %s", fn.Type())
+		}
+
+		if call, ok := result.(*tailCall); ok {
+			node, fn, args, named = call.node, call.fn, call.args, call.named
+			continue
+		}
+
+		for i := len(checks) - 1; i >= 0; i-- {
+			result = checkReturnValue(checks[i].node, checks[i].fn, result)
+			if isError(result) {
+				return result
+			}
+		}
+		return result
+	}
+}
+
+// stackOverflow reports a call exceeding MaxCallDepth along with the chain
+// of calls leading to it, innermost last.
+func stackOverflow(frame *object.Frame) *object.Error {
+	const maxCalls = 8
+
+	calls := frame.Calls()
+	chain := []string{}
+	if len(calls) > maxCalls {
+		chain = append(chain, "...")
+		calls = calls[len(calls)-maxCalls:]
+	}
+	for _, call := range calls {
+		description := call.Frame.Function
+		if call.Frame.Call != nil {
+			pos := call.Frame.Call.Token.Position()
+			description += fmt.Sprintf(" [%d:%d]", pos.Line(), pos.Column())
+		}
+		if call.Count > 1 {
+			description += fmt.Sprintf(" (%d times)", call.Count)
+		}
+		chain = append(chain, description)
+	}
+
+	return callError(frame.Call, "stack overflow: maximum call depth of %d exceeded: %s",
+		MaxCallDepth, strings.Join(chain, " -> "))
+}
+
+// extendFunctionEnv binds the arguments of a call to the parameters of fn.
+// Positional arguments are bound in order, the extra ones are collected by
+// the variadic parameter if any, then named arguments are bound and the
+// parameters left are set to their default value. Parameters are bound in
+// order so that a default value can refer to the parameters before it.
+func extendFunctionEnv(node *ast.CallExpression, fn *object.Function, args []object.Object, named []namedArgument, frame *object.Frame) (*object.Environment, object.Object) {
+	env := object.NewCallEnvironment(fn.Env, frame)
+
+	fixed := fn.Parameters
+	var variadic *ast.Parameter
+	if n := len(fixed); n != 0 && fixed[n-1].Variadic {
+		variadic = fixed[n-1]
+		fixed = fixed[:n-1]
+	}
+
+	if len(args) > len(fixed) && variadic == nil {
+		return nil, callError(node, "wrong number of arguments for %s: want %s, got %d",
+			functionName(fn), ast.Arity(fn.Parameters), len(args))
+	}
+
+	values := make([]object.Object, len(fixed))
+	copy(values, args)
+	for _, arg := range named {
+		i := ast.ParameterIndex(fixed, arg.name)
+		if i < 0 {
+			return nil, callError(node, "unknown parameter %s for %s", arg.name, functionName(fn))
+		}
+		if values[i] != nil {
+			return nil, callError(node, "argument %s of %s given twice", arg.name, functionName(fn))
+		}
+		values[i] = arg.value
+	}
+
+	for i, param := range fixed {
+		val := values[i]
+		if val == nil {
+			if param.Default == nil {
+				if len(named) == 0 {
+					return nil, callError(node, "wrong number of arguments for %s: want %s, got %d",
+						functionName(fn), ast.Arity(fn.Parameters), len(args))
+				}
+				return nil, callError(node, "missing argument %s for %s", param.Name.Value, functionName(fn))
+			}
+			val = Eval(param.Default, env)
+			if isError(val) {
+				return nil, val
+			}
+		}
+		val, err := checkArgument(node, fn, param, val)
+		if err != nil {
+			return nil, err
+		}
+		if param.Name.Token.Type == lexer.SELF {
+			env.SetMutable(param.Name.Value, val)
+		} else {
+			env.Set(param.Name.Value, val)
+		}
+	}
+
+	if variadic != nil {
+		rest := []object.Object{}
+		if len(args) > len(fixed) {
+			for _, arg := range args[len(fixed):] {
+				val, err := checkArgument(node, fn, variadic, arg)
+				if err != nil {
+					return nil, err
+				}
+				rest = append(rest, val)
+			}
+		}
+		env.Set(variadic.Name.Value, &object.Array{Elements: rest})
+	}
+
+	return env, nil
+}
+
+// checkArgument returns val converted to the declared type of param.
+func checkArgument(node *ast.CallExpression, fn *object.Function, param *ast.Parameter, val object.Object) (object.Object, *object.Error) {
+	if param.Type == nil {
+		return val, nil
+	}
+	converted := evalTypedValue(val, param.Type.Value, fn.Env)
+	if err, ok := converted.(*object.Error); ok {
+		return nil, callError(node, "argument %s of %s: %s", param.Name.Value, functionName(fn), err.Message)
+	}
+	return converted, nil
+}
+
+// builtinArguments places the named arguments of a builtin call at the
+// position of the parameter they name.
+func builtinArguments(node *ast.CallExpression, fn *object.Builtin, args []object.Object, named []namedArgument) ([]object.Object, *object.Error) {
+	if len(named) == 0 {
+		return args, nil
+	}
+	if fn.Parameters == nil {
+		return nil, callError(node, "%s does not accept named arguments", fn.Name)
+	}
+
+	values := make([]object.Object, len(fn.Parameters))
+	copy(values, args)
+	for _, arg := range named {
+		i := -1
+		for j, name := range fn.Parameters {
+			if name == arg.name {
+				i = j
+			}
+		}
+		if i < 0 {
+			return nil, callError(node, "unknown parameter %s for %s", arg.name, fn.Name)
+		}
+		if values[i] != nil {
+			return nil, callError(node, "argument %s of %s given twice", arg.name, fn.Name)
+		}
+		values[i] = arg.value
+	}
+
+	// optional trailing parameters may be left out, not the ones before a
+	// named argument
+	n := len(values)
+	for n > 0 && values[n-1] == nil {
+		n--
+	}
+	for i, val := range values[:n] {
+		if val == nil {
+			return nil, callError(node, "missing argument %s for %s", fn.Parameters[i], fn.Name)
+		}
+	}
+	return values[:n], nil
+}
+
+// checkReturnValue checks the value returned by fn against its return
+// signature and returns it converted to the declared types. Functions
+// returning several values return them as a tuple.
+func checkReturnValue(node *ast.CallExpression, fn *object.Function, val object.Object) object.Object {
+	if len(fn.ReturnTypes) == 0 {
+		return val
+	}
+
+	values := []object.Object{val}
+	if tuple, ok := val.(*object.Tuple); ok {
+		values = tuple.Elements
+	}
+	if len(values) != len(fn.ReturnTypes) {
+		return callError(node, "wrong number of return values for %s: want %d, got %d",
+			functionName(fn), len(fn.ReturnTypes), len(values))
+	}
+
+	converted := make([]object.Object, len(values))
+	for i, typ := range fn.ReturnTypes {
+		converted[i] = evalTypedValue(values[i], typ.Value, fn.Env)
+		if err, ok := converted[i].(*object.Error); ok {
+			return callError(node, "return value of %s: %s", functionName(fn), err.Message)
+		}
+	}
+	if len(converted) == 1 {
+		return converted[0]
+	}
+	return &object.Tuple{Elements: converted}
+}
+
+func unwrapReturnValue(obj object.Object) object.Object {
+	if returnValue, ok := obj.(*object.ReturnValue); ok {
+		return returnValue.Value
+	}
+	return obj
+}
+
+func functionName(fn *object.Function) string {
+	if fn.Name == nil {
+		return "anonymous function"
+	}
+	if fn.Owner != "" {
+		return fn.Owner + "." + fn.Name.Value
+	}
+	return fn.Name.Value
+}
+
+// callError returns an error located at the call node, if any.
+func callError(node *ast.CallExpression, format string, a ...interface{}) *object.Error {
+	if node == nil {
+		return newError(format, a...)
+	}
+	return newErrorAt(node.Token, format, a...)
+}
@@ -0,0 +1,233 @@
+package evaluator
+
+import (
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/poolpOrg/julu/object"
+)
+
+type integerKind struct {
+	width  int
+	signed bool
+}
+
+// sizedIntegers maps the fixed-width integer type names to their layout.
+// int and int64 are the same type, represented by object.Integer.
+var sizedIntegers = map[string]integerKind{
+	"int8":   {8, true},
+	"int16":  {16, true},
+	"int32":  {32, true},
+	"uint8":  {8, false},
+	"uint16": {16, false},
+	"uint32": {32, false},
+	"uint64": {64, false},
+}
+
+// integerRanges holds the bounds of the integer types a value can be cast
+// to. int64 bounds are expressed as floats since they are only used to check
+// float to integer conversions, the other conversions wrap like in C.
+var integerRanges = map[string]struct{ min, max float64 }{
+	"int":    {math.MinInt64, math.MaxInt64},
+	"int8":   {math.MinInt8, math.MaxInt8},
+	"int16":  {math.MinInt16, math.MaxInt16},
+	"int32":  {math.MinInt32, math.MaxInt32},
+	"int64":  {math.MinInt64, math.MaxInt64},
+	"uint8":  {0, math.MaxUint8},
+	"uint16": {0, math.MaxUint16},
+	"uint32": {0, math.MaxUint32},
+	"uint64": {0, math.MaxUint64},
+}
+
+// evalTypedValue checks that val can be bound to a name declared as
+// typeName, struct and union types are looked up in env. Integers are
+// converted to the declared integer or float type, wrapping like a C
+// assignment, other values must already have that type or be null for the
+// nullable types.
+func evalTypedValue(val object.Object, typeName string, env *object.Environment) object.Object {
+	if strings.HasPrefix(typeName, "[") && strings.HasSuffix(typeName, "]") {
+		return evalTypedArray(val, typeName, env)
+	}
+
+	expected, ok := object.DeclaredTypes[typeName]
+	if !ok {
+		definition, _ := env.Get(typeName)
+		switch definition := definition.(type) {
+		case *object.StructType:
+			if s, ok := val.(*object.Struct); !ok || s.Definition != definition {
+				return newError("cannot use %s as %s", val.Type(), typeName)
+			}
+			return val
+		case *object.UnionType:
+			if u, ok := val.(*object.Union); !ok || u.Definition != definition {
+				return newError("cannot use %s as %s", val.Type(), typeName)
+			}
+			return val
+		case *object.NamedType:
+			if n, ok := val.(*object.Named); !ok || n.Definition != definition {
+				return newError("cannot use %s as %s", val.Type(), typeName)
+			}
+			return val
+		case *object.TypeAlias:
+			return evalTypedValue(val, definition.Target, definition.Env)
+		}
+		return newError("unknown type: %s", typeName)
+	}
+
+	if isInteger(val) && (expected == object.FLOAT_OBJ || isIntegerType(expected)) {
+		return evalCastExpression(val, typeName)
+	}
+	if val.Type() == object.FLOAT_OBJ && typeName == "float32" {
+		return evalCastExpression(val, typeName)
+	}
+	if val == NULL && object.NullableTypes[expected] {
+		return val
+	}
+	if val.Type() != expected {
+		return newError("cannot use %s as %s", val.Type(), typeName)
+	}
+	return val
+}
+
+// evalTypedArray checks that val is an array, or null, whose elements can be
+// bound to the element type of the array type typeName, [T].
+func evalTypedArray(val object.Object, typeName string, env *object.Environment) object.Object {
+	if val == NULL {
+		return val
+	}
+	array, ok := val.(*object.Array)
+	if !ok {
+		return newError("cannot use %s as %s", val.Type(), typeName)
+	}
+
+	// the array is only copied if an element had to be converted, so that
+	// it is shared with the caller otherwise
+	elemType := typeName[1 : len(typeName)-1]
+	elements := make([]object.Object, len(array.Elements))
+	converted := false
+	for i, elem := range array.Elements {
+		elements[i] = evalTypedValue(elem, elemType, env)
+		if err, ok := elements[i].(*object.Error); ok {
+			return newError("%s in element %d of %s", err.Message, i, typeName)
+		}
+		converted = converted || elements[i] != elem
+	}
+	if !converted {
+		return array
+	}
+	return &object.Array{Elements: elements}
+}
+
+func evalCastExpression(obj object.Object, typeName string) object.Object {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return castInteger(uint64(obj.Value), true, typeName, obj)
+	case *object.SizedInteger:
+		return castInteger(uint64(obj.Int64()), obj.Signed, typeName, obj)
+	case *object.Char:
+		return castInteger(uint64(obj.Value), true, typeName, obj)
+	case *object.Boolean:
+		if typeName == "bool" {
+			return obj
+		}
+		if obj.Value {
+			return castInteger(1, true, typeName, obj)
+		}
+		return castInteger(0, true, typeName, obj)
+	case *object.Float:
+		return castFloat(obj.Value, typeName, obj)
+	default:
+		// other values only convert to their own type
+		if object.DeclaredTypes[typeName] == obj.Type() {
+			return obj
+		}
+		return newError("cannot convert %s to %s", obj.Type(), typeName)
+	}
+}
+
+// castInteger converts the two's complement bits of an integer, signed or
+// not, to typeName.
+func castInteger(bits uint64, signed bool, typeName string, obj object.Object) object.Object {
+	if kind, ok := sizedIntegers[typeName]; ok {
+		return object.NewSizedInteger(bits, kind.width, kind.signed)
+	}
+
+	switch typeName {
+	case "int", "int64":
+		return &object.Integer{Value: int64(bits)}
+	case "float", "float64":
+		if signed {
+			return &object.Float{Value: float64(int64(bits))}
+		}
+		return &object.Float{Value: float64(bits)}
+	case "float32":
+		if signed {
+			return &object.Float{Value: float64(float32(int64(bits)))}
+		}
+		return &object.Float{Value: float64(float32(bits))}
+	case "bool":
+		return nativeBoolToBooleanObject(bits != 0)
+	case "char":
+		if (signed && int64(bits) < 0) || bits > unicode.MaxRune {
+			return newError("cannot convert %s to char: out of range", obj.Inspect())
+		}
+		return &object.Char{Value: rune(bits)}
+	default:
+		return newError("cannot convert %s to %s", obj.Type(), typeName)
+	}
+}
+
+func castFloat(value float64, typeName string, obj object.Object) object.Object {
+	switch typeName {
+	case "float", "float64":
+		return &object.Float{Value: value}
+	case "float32":
+		return &object.Float{Value: float64(float32(value))}
+	case "bool":
+		return nativeBoolToBooleanObject(value != 0)
+	}
+
+	bounds, ok := integerRanges[typeName]
+	if !ok {
+		return newError("cannot convert %s to %s", obj.Type(), typeName)
+	}
+
+	// conversion truncates toward zero, values that don't fit the target
+	// type are undefined behaviour in C so they are rejected instead
+	value = math.Trunc(value)
+	if math.IsNaN(value) || value < bounds.min || value >= bounds.max+1 {
+		return newError("cannot convert %s to %s: out of range", obj.Inspect(), typeName)
+	}
+	if value >= 0 {
+		return castInteger(uint64(value), false, typeName, obj)
+	}
+	return castInteger(uint64(int64(value)), true, typeName, obj)
+}
+
+func isInteger(obj object.Object) bool {
+	return isIntegerType(obj.Type())
+}
+
+func isIntegerType(t object.ObjectType) bool {
+	switch t {
+	case object.INTEGER_OBJ,
+		object.INT8_OBJ, object.INT16_OBJ, object.INT32_OBJ,
+		object.UINT8_OBJ, object.UINT16_OBJ, object.UINT32_OBJ, object.UINT64_OBJ:
+		return true
+	}
+	return false
+}
+
+// integerValue returns the value of any integer object as an int64, unsigned
+// values above math.MaxInt64 wrap around.
+func integerValue(obj object.Object) (int64, bool) {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return obj.Value, true
+	case *object.SizedInteger:
+		return obj.Int64(), true
+	default:
+		return 0, false
+	}
+}
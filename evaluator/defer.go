@@ -0,0 +1,116 @@
+package evaluator
+
+import (
+	"github.com/poolpOrg/julu/ast"
+	"github.com/poolpOrg/julu/object"
+)
+
+// deferredCall is a call scheduled by a defer statement. The function and
+// the arguments of a deferred call expression are evaluated by the defer
+// statement, a deferred function is called with the result of the block.
+type deferredCall struct {
+	stmt   *ast.DeferStatement
+	node   *ast.CallExpression
+	fn     object.Object
+	args   []object.Object
+	named  []namedArgument
+	result bool
+}
+
+// deferredCalls are the calls deferred by the statements of a block, they
+// run in reverse order when the block exits, whatever the reason.
+type deferredCalls []*deferredCall
+
+// add evaluates a defer statement, it returns an error if the call cannot
+// be scheduled and nil otherwise.
+func (d *deferredCalls) add(stmt *ast.DeferStatement, env *object.Environment) object.Object {
+	call := &deferredCall{stmt: stmt}
+
+	if node, ok := stmt.Call.(*ast.CallExpression); ok {
+		call.node = node
+		call.fn = Eval(node.Function, env)
+		if isError(call.fn) {
+			return call.fn
+		}
+		args, named, err := evalArguments(node.Parameters, env)
+		if err != nil {
+			return err
+		}
+		call.args, call.named = args, named
+	} else {
+		call.fn = Eval(stmt.Call, env)
+		if isError(call.fn) {
+			return call.fn
+		}
+		call.result = true
+	}
+
+	switch call.fn.(type) {
+	case *object.Function, *object.Builtin, *object.BoundMethod:
+	default:
+		return newErrorAt(stmt.Token, "cannot defer %s, want a call or a function", call.fn.Type())
+	}
+
+	*d = append(*d, call)
+	return nil
+}
+
+// run runs the deferred calls once the block evaluated to result and returns
+// the result of the block. A pending tail call is made first, so that it
+// runs before the deferred calls, and an error raised by a deferred call
+// replaces the result unless it already is an error.
+func (d deferredCalls) run(result object.Object, env *object.Environment) object.Object {
+	if len(d) == 0 {
+		return result
+	}
+
+	result = completeTailCall(result, env)
+	value := blockValue(result)
+	for i := len(d) - 1; i >= 0; i-- {
+		call := d[i]
+		args := call.args
+		if fn, ok := call.fn.(*object.Function); ok && call.result && len(fn.Parameters) != 0 {
+			args = []object.Object{value}
+		}
+
+		out := locate(callFunction(env.Frame(), call.node, call.fn, args, call.named), call.stmt.Token, env)
+		if err, ok := out.(*object.Error); ok {
+			if _, failed := result.(*object.Error); !failed {
+				result = err
+			}
+		}
+	}
+	return result
+}
+
+// completeTailCall makes the tail call a block evaluated to, if any.
+func completeTailCall(result object.Object, env *object.Environment) object.Object {
+	switch r := result.(type) {
+	case *tailCall:
+		return locate(callFunction(env.Frame(), r.node, r.fn, r.args, r.named), r.node.Token, env)
+
+	case *object.ReturnValue:
+		if call, ok := r.Value.(*tailCall); ok {
+			value := completeTailCall(call, env)
+			if err, ok := value.(*object.Error); ok {
+				return err
+			}
+			return &object.ReturnValue{Value: value}
+		}
+	}
+	return result
+}
+
+// blockValue is the value passed to deferred functions: the value returned
+// or evaluated to by the block, null if it was interrupted otherwise.
+func blockValue(result object.Object) object.Object {
+	switch r := result.(type) {
+	case nil:
+		return NULL
+	case *object.ReturnValue:
+		return r.Value
+	case *object.Error, *object.Break, *object.Continue:
+		return NULL
+	}
+	return result
+}
@@ -3,7 +3,11 @@ package evaluator
 import (
 	"bufio"
 	"fmt"
+	"math"
+	"math/bits"
+	"sort"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/poolpOrg/julu/ast"
 	"github.com/poolpOrg/julu/lexer"
@@ -12,28 +16,46 @@ import (
 )
 
 var (
-	NULL     = &object.Null{}
-	TRUE     = &object.Boolean{Value: true}
-	FALSE    = &object.Boolean{Value: false}
-	BREAK    = &object.Break{}
-	CONTINUE = &object.Continue{}
-	VOID     = &object.Void{}
+	NULL  = &object.Null{}
+	TRUE  = &object.Boolean{Value: true}
+	FALSE = &object.Boolean{Value: false}
+	VOID  = &object.Void{}
+	NONE  = &object.Option{}
 )
 
+// MaxCallDepth is the maximum number of nested function calls, a call
+// exceeding it fails with a stack overflow error. Tail calls don't nest.
+var MaxCallDepth = 10000
+
+const TAIL_CALL_OBJ = "TAIL_CALL"
+
 func Eval(node ast.Node, env *object.Environment) object.Object {
 	switch node := node.(type) {
 	case *ast.Program:
 		return evalStatements(node.Statements, env)
 
 	case *ast.LetStatement:
+		if node.Names != nil {
+			return evalDestructuringLetStatement(node, env)
+		}
 		val := Eval(node.Value, env)
 		if isError(val) {
 			return val
 		}
-		env.Set(node.Name.Value, val)
+		if node.Type != nil {
+			val = evalTypedValue(val, node.Type.Value, env)
+			if isError(val) {
+				return locate(val, node.Token, env)
+			}
+		}
+		if node.Mutable {
+			env.SetMutable(node.Name.Value, val)
+		} else {
+			env.Set(node.Name.Value, val)
+		}
 
 	case *ast.Identifier:
-		return evalIdentifier(node, env)
+		return locate(evalIdentifier(node, env), node.Token, env)
 
 	case *ast.ExpressionStatement:
 		return Eval(node.Expression, env)
@@ -43,9 +65,12 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		if isError(right) {
 			return right
 		}
-		return evalPrefixExpression(node.Operator, right)
+		return locate(evalPrefixExpression(node.Operator, right), node.Token, env)
 
 	case *ast.InfixExpression:
+		if isLogicalOperator(node.Operator) {
+			return evalLogicalExpression(node, env)
+		}
 		left := Eval(node.Left, env)
 		if isError(left) {
 			return left
@@ -54,7 +79,10 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		if isError(right) {
 			return right
 		}
-		return evalInfixExpression(node.Operator, left, right)
+		return locate(evalInfixExpression(node.Operator, left, right), node.Token, env)
+
+	case *ast.AssignExpression:
+		return locate(evalAssignExpression(node, env), node.Token, env)
 
 	case *ast.BlockStatement:
 		return evalBlockStatement(node, env)
@@ -65,7 +93,22 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 	case *ast.MatchExpression:
 		return evalMatchExpression(node, env)
 
+	case *ast.TupleLiteral:
+		elements := evalExpressions(node.Elements, env)
+		if len(elements) == 1 && isError(elements[0]) {
+			return elements[0]
+		}
+		return &object.Tuple{Elements: elements}
+
 	case *ast.ReturnStatement:
+		if call, ok := node.ReturnValue.(*ast.CallExpression); ok && env.Frame() != nil {
+			// returning the result of a call from a function is a tail call
+			val := evalTailCall(call, env)
+			if isError(val) {
+				return val
+			}
+			return &object.ReturnValue{Value: val}
+		}
 		val := Eval(node.ReturnValue, env)
 		if isError(val) {
 			return val
@@ -77,49 +120,101 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 
 	case *ast.FunctionLiteral:
 		if node.Name != nil {
-			funcObj := &object.Function{Name: node.Name, Parameters: node.Parameters, Body: node.Body, Env: env}
+			funcObj := &object.Function{Name: node.Name, Parameters: node.Parameters, ReturnTypes: node.ReturnTypes, Body: node.Body, Env: env}
 			env.Set(node.Name.Value, funcObj)
 			return funcObj
 		} else {
-			return &object.Function{Parameters: node.Parameters, Body: node.Body, Env: env}
+			return &object.Function{Parameters: node.Parameters, ReturnTypes: node.ReturnTypes, Body: node.Body, Env: env}
 		}
 
 	case *ast.CallExpression:
-		fn := Eval(node.Function, env)
-		if isError(fn) {
-			return fn
-		}
-		args := evalExpressions(node.Parameters, env)
-		if len(args) == 1 && isError(args[0]) {
-			return args[0]
-		}
-		return applyFunction(fn, args)
+		return evalCallExpression(node, env)
 
 	case *ast.LoopStatement:
 		return evalLoopStatement(node, env)
 
+	case *ast.ForStatement:
+		return locate(evalForStatement(node, env), node.Token, env)
+
+	case *ast.WithStatement:
+		return evalWithStatement(node, env)
+
+	case *ast.StructStatement:
+		return evalStructStatement(node, env)
+
+	case *ast.UnionStatement:
+		return evalUnionStatement(node, env)
+
+	case *ast.TypeStatement:
+		return locate(evalTypeStatement(node, env), node.Token, env)
+
+	case *ast.MethodBlockStatement:
+		return locate(evalMethodBlockStatement(node, env), node.Token, env)
+
+	case *ast.StructLiteral:
+		return locate(evalStructLiteral(node, env), node.Token, env)
+
+	case *ast.FieldExpression:
+		left := Eval(node.Left, env)
+		if isError(left) {
+			return left
+		}
+		return locate(evalFieldExpression(left, node.Field.Value), node.Token, env)
+
 	case *ast.BreakStatement:
-		return BREAK
+		return evalBreakStatement(node, env)
 	case *ast.ContinueStatement:
-		return CONTINUE
+		if node.Label != nil {
+			return &object.Continue{Label: node.Label.Value}
+		}
+		return &object.Continue{}
+
+	case *ast.CastExpression:
+		left := Eval(node.Left, env)
+		if isError(left) {
+			return left
+		}
+		return locate(evalNamedCast(left, node.Type.Value, env), node.Token, env)
 
 	case *ast.IntegerLiteral:
-		return &object.Integer{Value: node.Value}
+		var val object.Object = &object.Integer{Value: node.Value}
+		if node.Unsigned {
+			val = object.NewSizedInteger(uint64(node.Value), 64, false)
+		}
+		if node.Cast != nil {
+			return evalNamedCast(val, node.Cast.Value, env)
+		}
+		return val
 
 	case *ast.FloatLiteral:
+		if node.Cast != nil {
+			return evalNamedCast(&object.Float{Value: node.Value}, node.Cast.Value, env)
+		}
 		return &object.Float{Value: node.Value}
 
 	case *ast.Boolean:
+		if node.Cast != nil {
+			return evalNamedCast(nativeBoolToBooleanObject(node.Value), node.Cast.Value, env)
+		}
 		return nativeBoolToBooleanObject(node.Value)
 
+	case *ast.CharLiteral:
+		return &object.Char{Value: node.Value}
+
 	case *ast.Null:
 		return NULL
 
+	case *ast.None:
+		return NONE
+
+	case *ast.TryExpression:
+		return evalTryExpression(node, env)
+
 	case *ast.StringLiteral:
 		return &object.String{Value: node.Value}
 
 	case *ast.FStringLiteral:
-		return evalFStringLiteral(node, env)
+		return locate(evalFStringLiteral(node, env), node.Token, env)
 
 	case *ast.ArrayLiteral:
 		elements := evalExpressions(node.Elements, env)
@@ -129,7 +224,7 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		return &object.Array{Elements: elements}
 
 	case *ast.HashLiteral:
-		return evalHashLiteral(node, env)
+		return locate(evalHashLiteral(node, env), node.Token, env)
 
 	case *ast.IndexExpression:
 		left := Eval(node.Left, env)
@@ -140,7 +235,7 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		if isError(index) {
 			return index
 		}
-		return evalIndexExpression(left, index)
+		return locate(evalIndexExpression(left, index), node.Token, env)
 
 	default:
 		return newError("unknown node type: %T", node)
@@ -148,18 +243,22 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 	return nil
 }
 
-func EvalFunctionObject(fn object.Object, env *object.Environment) object.Object {
-	if fn.Type() != object.FUNCTION_OBJ {
+// EvalMain calls the main function of a program. If main declares a
+// parameter, it receives the script arguments as an array of strings.
+func EvalMain(fn object.Object, args []string) object.Object {
+	main, ok := fn.(*object.Function)
+	if !ok {
 		return newError("not a function: %s", fn.Type())
 	}
+	if len(main.Parameters) == 0 {
+		return applyFunction(main, []object.Object{})
+	}
 
-	fn = fn.(*object.Function)
-	// evaluate function parameters and match with prototype
-	//args := evalExpressions(fn.Parameters, env)
-	//if len(args) == 1 && isError(args[0]) {
-	//	return args[0]
-	//}
-	return applyFunction(fn, []object.Object{})
+	elements := make([]object.Object, len(args))
+	for i, arg := range args {
+		elements[i] = &object.String{Value: arg}
+	}
+	return applyFunction(main, []object.Object{&object.Array{Elements: elements}})
 }
 
 func nativeBoolToBooleanObject(input bool) *object.Boolean {
@@ -171,23 +270,39 @@ func nativeBoolToBooleanObject(input bool) *object.Boolean {
 
 func evalStatements(stmts []ast.Statement, env *object.Environment) object.Object {
 	var result object.Object
+	var defers deferredCalls
 
 	for _, stmt := range stmts {
+		if stmt, ok := stmt.(*ast.DeferStatement); ok {
+			if result = defers.add(stmt, env); result != nil {
+				break
+			}
+			continue
+		}
 		result = Eval(stmt, env)
-		if returnValue, ok := result.(*object.ReturnValue); ok {
-			return returnValue.Value
+		if isError(result) {
+			break
 		}
 	}
 
+	result = defers.run(result, env)
+	if returnValue, ok := result.(*object.ReturnValue); ok {
+		return returnValue.Value
+	}
 	return result
 }
 
 func evalPrefixExpression(operator string, right object.Object) object.Object {
+	if named, ok := right.(*object.Named); ok {
+		return wrapNamed(named.Definition, named.Value, evalPrefixExpression(operator, named.Value))
+	}
 	switch operator {
-	case "!":
+	case "!", "not":
 		return evalBangOperatorExpression(right)
 	case "-":
 		return evalMinusPrefixOperatorExpression(right)
+	case "~":
+		return evalBitwiseNotOperatorExpression(right)
 	default:
 		return newError("unknown operator: %s%s", operator, right.Type())
 	}
@@ -197,8 +312,33 @@ func evalInfixExpression(operator string, left, right object.Object) object.Obje
 	switch {
 	case left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ:
 		return evalIntegerInfixExpression(operator, left, right)
+	case isInteger(left) && isInteger(right):
+		return evalSizedIntegerInfixExpression(operator, left, right)
+	case left.Type() == object.FLOAT_OBJ && right.Type() == object.FLOAT_OBJ:
+		return evalFloatInfixExpression(operator, left, right)
+
+	// an integer mixed with a float is promoted to float before evaluation
+	case isInteger(left) && right.Type() == object.FLOAT_OBJ:
+		return evalFloatInfixExpression(operator, integerToFloat(left), right)
+	case left.Type() == object.FLOAT_OBJ && isInteger(right):
+		return evalFloatInfixExpression(operator, left, integerToFloat(right))
+
 	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
 		return evalStringInfixExpression(operator, left, right)
+	case left.Type() == object.BOOLEAN_OBJ && right.Type() == object.BOOLEAN_OBJ:
+		return evalBooleanInfixExpression(operator, left, right)
+	case left.Type() == object.CHAR_OBJ && right.Type() == object.CHAR_OBJ:
+		return evalCharInfixExpression(operator, left, right)
+	case left == NULL || right == NULL:
+		return evalNullInfixExpression(operator, left, right)
+	case left.Type() == right.Type() && (left.Type() == object.RESULT_OBJ || left.Type() == object.OPTION_OBJ):
+		return evalVariantInfixExpression(operator, left, right)
+	case isNamed(left) || isNamed(right):
+		return evalNamedInfixExpression(operator, left, right)
+	case isStruct(left) && isStruct(right) && left.Type() == right.Type():
+		return evalStructInfixExpression(operator, left.(*object.Struct), right.(*object.Struct))
+	case isUnion(left) && isUnion(right) && left.Type() == right.Type():
+		return evalUnionInfixExpression(operator, left.(*object.Union), right.(*object.Union))
 	case left.Type() != right.Type():
 		return newError("type mismatch: %s %s %s", left.Type(), operator, right.Type())
 	default:
@@ -206,26 +346,72 @@ func evalInfixExpression(operator string, left, right object.Object) object.Obje
 	}
 }
 
+func integerToFloat(obj object.Object) object.Object {
+	return evalCastExpression(obj, "float")
+}
+
 func evalBangOperatorExpression(right object.Object) object.Object {
-	switch right {
-	case TRUE: //!true = false
-		return FALSE
-	case FALSE: //!false = true
-		return TRUE
-	case NULL: //!null = true
-		return TRUE
-	default: //!5 = false
-		return FALSE
+	// !null = true, !5 = false
+	return nativeBoolToBooleanObject(!isTruthy(right))
+}
+
+func isLogicalOperator(operator string) bool {
+	switch operator {
+	case "&&", "and", "||", "or":
+		return true
+	}
+	return false
+}
+
+// evalLogicalExpression evaluates && and || on operands of any type, the
+// right operand is only evaluated when the left one doesn't decide the
+// result.
+func evalLogicalExpression(node *ast.InfixExpression, env *object.Environment) object.Object {
+	left := Eval(node.Left, env)
+	if isError(left) {
+		return left
+	}
+
+	switch node.Operator {
+	case "&&", "and":
+		if !isTruthy(left) {
+			return FALSE
+		}
+	case "||", "or":
+		if isTruthy(left) {
+			return TRUE
+		}
+	}
+
+	right := Eval(node.Right, env)
+	if isError(right) {
+		return right
 	}
+	return nativeBoolToBooleanObject(isTruthy(right))
 }
 
 func evalMinusPrefixOperatorExpression(right object.Object) object.Object {
-	if right.Type() != object.INTEGER_OBJ {
+	switch right := right.(type) {
+	case *object.Integer:
+		return &object.Integer{Value: -right.Value}
+	case *object.SizedInteger:
+		return object.NewSizedInteger(-right.Value, right.Width, right.Signed)
+	case *object.Float:
+		return &object.Float{Value: -right.Value}
+	default:
 		return newError("unknown operator: -%s", right.Type())
 	}
+}
 
-	value := right.(*object.Integer).Value
-	return &object.Integer{Value: -value}
+func evalBitwiseNotOperatorExpression(right object.Object) object.Object {
+	switch right := right.(type) {
+	case *object.Integer:
+		return &object.Integer{Value: ^right.Value}
+	case *object.SizedInteger:
+		return object.NewSizedInteger(^right.Value, right.Width, right.Signed)
+	default:
+		return newError("unknown operator: ~%s", right.Type())
+	}
 }
 
 func evalIntegerInfixExpression(operator string, left, right object.Object) object.Object {
@@ -240,14 +426,35 @@ func evalIntegerInfixExpression(operator string, left, right object.Object) obje
 	case "*":
 		return &object.Integer{Value: leftVal * rightVal}
 	case "/":
+		if rightVal == 0 {
+			return newError("division by zero: %d / %d", leftVal, rightVal)
+		}
 		return &object.Integer{Value: leftVal / rightVal}
 	case "%":
+		if rightVal == 0 {
+			return newError("division by zero: %d %% %d", leftVal, rightVal)
+		}
 		return &object.Integer{Value: leftVal % rightVal}
+	case "**":
+		if rightVal < 0 {
+			return newError("negative exponent: %d ** %d", leftVal, rightVal)
+		}
+		return &object.Integer{Value: int64(integerPow(uint64(leftVal), uint64(rightVal)))}
 
 	case "<<":
+		if rightVal < 0 {
+			return newError("negative shift count: %d", rightVal)
+		}
 		return &object.Integer{Value: leftVal << rightVal}
 	case ">>":
+		if rightVal < 0 {
+			return newError("negative shift count: %d", rightVal)
+		}
 		return &object.Integer{Value: leftVal >> rightVal}
+	case "<<<":
+		return &object.Integer{Value: int64(rotateLeft(uint64(leftVal), 64, rightVal))}
+	case ">>>":
+		return &object.Integer{Value: int64(rotateLeft(uint64(leftVal), 64, -rightVal))}
 
 	case "&":
 		return &object.Integer{Value: leftVal & rightVal}
@@ -271,11 +478,309 @@ func evalIntegerInfixExpression(operator string, left, right object.Object) obje
 	case "!=":
 		return nativeBoolToBooleanObject(leftVal != rightVal)
 
-	case "&&", "and":
-		return nativeBoolToBooleanObject(leftVal != 0 && rightVal != 0)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
 
-	case "||", "or":
-		return nativeBoolToBooleanObject(leftVal != 0 || rightVal != 0)
+// evalSizedIntegerInfixExpression evaluates operators on fixed-width
+// integers. Both operands must have the same type, except for plain int
+// operands which are converted to the type of the other operand and for
+// shift counts which can be of any integer type. Results wrap around to the
+// operand width, comparisons, division and right shifts honour signedness.
+func evalSizedIntegerInfixExpression(operator string, left, right object.Object) object.Object {
+	switch operator {
+	case "<<", ">>", "<<<", ">>>", "**":
+		count, _ := integerValue(right)
+		if left.Type() == object.INTEGER_OBJ {
+			return evalIntegerInfixExpression(operator, left, &object.Integer{Value: count})
+		}
+		return evalIntegerShift(operator, left.(*object.SizedInteger), count)
+	}
+
+	if left.Type() == object.INTEGER_OBJ {
+		left = evalCastExpression(left, typeNameOf(right))
+	} else if right.Type() == object.INTEGER_OBJ {
+		right = evalCastExpression(right, typeNameOf(left))
+	} else if left.Type() != right.Type() {
+		return newError("type mismatch: %s %s %s", left.Type(), operator, right.Type())
+	}
+
+	l := left.(*object.SizedInteger)
+	r := right.(*object.SizedInteger)
+	width, signed := l.Width, l.Signed
+
+	switch operator {
+	case "+":
+		return object.NewSizedInteger(l.Value+r.Value, width, signed)
+	case "-":
+		return object.NewSizedInteger(l.Value-r.Value, width, signed)
+	case "*":
+		return object.NewSizedInteger(l.Value*r.Value, width, signed)
+	case "/":
+		if r.Value == 0 {
+			return newError("division by zero: %s / %s", l.Inspect(), r.Inspect())
+		}
+		if signed {
+			return object.NewSizedInteger(uint64(l.Int64()/r.Int64()), width, signed)
+		}
+		return object.NewSizedInteger(l.Value/r.Value, width, signed)
+	case "%":
+		if r.Value == 0 {
+			return newError("division by zero: %s %% %s", l.Inspect(), r.Inspect())
+		}
+		if signed {
+			return object.NewSizedInteger(uint64(l.Int64()%r.Int64()), width, signed)
+		}
+		return object.NewSizedInteger(l.Value%r.Value, width, signed)
+
+	case "&":
+		return object.NewSizedInteger(l.Value&r.Value, width, signed)
+	case "|":
+		return object.NewSizedInteger(l.Value|r.Value, width, signed)
+	case "^":
+		return object.NewSizedInteger(l.Value^r.Value, width, signed)
+
+	case "<", "<=", ">", ">=":
+		cmp := 0
+		if signed {
+			cmp = compareOrdered(l.Int64(), r.Int64())
+		} else {
+			cmp = compareOrdered(l.Value, r.Value)
+		}
+		switch operator {
+		case "<":
+			return nativeBoolToBooleanObject(cmp < 0)
+		case "<=":
+			return nativeBoolToBooleanObject(cmp <= 0)
+		case ">":
+			return nativeBoolToBooleanObject(cmp > 0)
+		default:
+			return nativeBoolToBooleanObject(cmp >= 0)
+		}
+	case "==", "is":
+		return nativeBoolToBooleanObject(l.Value == r.Value)
+	case "!=":
+		return nativeBoolToBooleanObject(l.Value != r.Value)
+
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+// evalIntegerShift evaluates the operators of fixed-width integers taking
+// an amount of any integer type as right operand. Right shifts are
+// arithmetic for signed integers and logical for unsigned ones, rotations
+// wrap around the integer width.
+func evalIntegerShift(operator string, left *object.SizedInteger, count int64) object.Object {
+	switch operator {
+	case "**":
+		if count < 0 {
+			return newError("negative exponent: %s ** %d", left.Inspect(), count)
+		}
+		return object.NewSizedInteger(integerPow(left.Value, uint64(count)), left.Width, left.Signed)
+	case "<<<":
+		return object.NewSizedInteger(rotateLeft(left.Value, left.Width, count), left.Width, left.Signed)
+	case ">>>":
+		return object.NewSizedInteger(rotateLeft(left.Value, left.Width, -count), left.Width, left.Signed)
+	}
+
+	if count < 0 {
+		return newError("negative shift count: %d", count)
+	}
+	if operator == "<<" {
+		return object.NewSizedInteger(left.Value<<count, left.Width, left.Signed)
+	}
+	if left.Signed {
+		return object.NewSizedInteger(uint64(left.Int64()>>count), left.Width, left.Signed)
+	}
+	return object.NewSizedInteger(left.Value>>count, left.Width, left.Signed)
+}
+
+// rotateLeft rotates the low width bits of value by count, rotating right
+// when count is negative.
+func rotateLeft(value uint64, width int, count int64) uint64 {
+	if width == 64 {
+		return bits.RotateLeft64(value, int(count%64))
+	}
+	k := uint64((count%int64(width) + int64(width)) % int64(width))
+	if k == 0 {
+		return value
+	}
+	return value<<k | value>>(uint64(width)-k)
+}
+
+// integerPow computes base ** exp by squaring, the low bits of the result
+// are the same for signed and unsigned integers.
+func integerPow(base, exp uint64) uint64 {
+	result := uint64(1)
+	for exp > 0 {
+		if exp&1 == 1 {
+			result *= base
+		}
+		base *= base
+		exp >>= 1
+	}
+	return result
+}
+
+func compareOrdered[T int64 | uint64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// typeNameOf returns the julu type name of a numeric object.
+func typeNameOf(obj object.Object) string {
+	switch obj.Type() {
+	case object.INTEGER_OBJ:
+		return "int"
+	case object.FLOAT_OBJ:
+		return "float"
+	default:
+		return strings.ToLower(string(obj.Type()))
+	}
+}
+
+func evalFloatInfixExpression(operator string, left, right object.Object) object.Object {
+	leftVal := left.(*object.Float).Value
+	rightVal := right.(*object.Float).Value
+
+	switch operator {
+	case "+":
+		return &object.Float{Value: leftVal + rightVal}
+	case "-":
+		return &object.Float{Value: leftVal - rightVal}
+	case "*":
+		return &object.Float{Value: leftVal * rightVal}
+	case "/":
+		if rightVal == 0 {
+			return newError("division by zero: %s / %s", left.Inspect(), right.Inspect())
+		}
+		return &object.Float{Value: leftVal / rightVal}
+	case "%":
+		if rightVal == 0 {
+			return newError("division by zero: %s %% %s", left.Inspect(), right.Inspect())
+		}
+		return &object.Float{Value: math.Mod(leftVal, rightVal)}
+	case "**":
+		return &object.Float{Value: math.Pow(leftVal, rightVal)}
+
+	case "<":
+		return nativeBoolToBooleanObject(leftVal < rightVal)
+	case "<=":
+		return nativeBoolToBooleanObject(leftVal <= rightVal)
+	case ">":
+		return nativeBoolToBooleanObject(leftVal > rightVal)
+	case ">=":
+		return nativeBoolToBooleanObject(leftVal >= rightVal)
+	case "==", "is":
+		return nativeBoolToBooleanObject(leftVal == rightVal)
+	case "!=":
+		return nativeBoolToBooleanObject(leftVal != rightVal)
+
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+func evalBooleanInfixExpression(operator string, left, right object.Object) object.Object {
+	leftVal := left.(*object.Boolean).Value
+	rightVal := right.(*object.Boolean).Value
+
+	switch operator {
+	case "==", "is":
+		return nativeBoolToBooleanObject(leftVal == rightVal)
+	case "!=":
+		return nativeBoolToBooleanObject(leftVal != rightVal)
+
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+// evalVariantInfixExpression compares two results or two options, they are
+// equal if built by the same constructor with equal values.
+func evalVariantInfixExpression(operator string, left, right object.Object) object.Object {
+	var equal bool
+	switch left := left.(type) {
+	case *object.Result:
+		right := right.(*object.Result)
+		equal = left.Ok == right.Ok && valuesEqual(left.Value, right.Value)
+	case *object.Option:
+		right := right.(*object.Option)
+		equal = left.Value == right.Value ||
+			(left.Value != nil && right.Value != nil && valuesEqual(left.Value, right.Value))
+	}
+
+	switch operator {
+	case "==", "is":
+		return nativeBoolToBooleanObject(equal)
+	case "!=":
+		return nativeBoolToBooleanObject(!equal)
+	}
+	return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+}
+
+// evalTryExpression unwraps an ok result or some option, an err result or
+// none is returned from the enclosing function as is.
+func evalTryExpression(node *ast.TryExpression, env *object.Environment) object.Object {
+	val := Eval(node.Value, env)
+	if isError(val) {
+		return val
+	}
+
+	switch val := val.(type) {
+	case *object.Result:
+		if val.Ok {
+			return val.Value
+		}
+		return &object.ReturnValue{Value: val}
+	case *object.Option:
+		if val.Value != nil {
+			return val.Value
+		}
+		return &object.ReturnValue{Value: val}
+	}
+	return newErrorAt(node.Token, "cannot use ? on %s, want RESULT or OPTION", val.Type())
+}
+
+// evalNullInfixExpression compares null to a value of any type so that
+// x != null can guard the use of x.
+func evalNullInfixExpression(operator string, left, right object.Object) object.Object {
+	switch operator {
+	case "==", "is":
+		return nativeBoolToBooleanObject(left == right)
+	case "!=":
+		return nativeBoolToBooleanObject(left != right)
+	}
+	if left.Type() != right.Type() {
+		return newError("type mismatch: %s %s %s", left.Type(), operator, right.Type())
+	}
+	return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+}
+
+func evalCharInfixExpression(operator string, left, right object.Object) object.Object {
+	leftVal := left.(*object.Char).Value
+	rightVal := right.(*object.Char).Value
+
+	switch operator {
+	case "<":
+		return nativeBoolToBooleanObject(leftVal < rightVal)
+	case "<=":
+		return nativeBoolToBooleanObject(leftVal <= rightVal)
+	case ">":
+		return nativeBoolToBooleanObject(leftVal > rightVal)
+	case ">=":
+		return nativeBoolToBooleanObject(leftVal >= rightVal)
+	case "==", "is":
+		return nativeBoolToBooleanObject(leftVal == rightVal)
+	case "!=":
+		return nativeBoolToBooleanObject(leftVal != rightVal)
 
 	default:
 		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
@@ -305,17 +810,172 @@ func evalStringInfixExpression(operator string, left, right object.Object) objec
 		return nativeBoolToBooleanObject(leftVal == rightVal)
 	case "!=":
 		return nativeBoolToBooleanObject(leftVal != rightVal)
-	case "&&", "and":
-		return nativeBoolToBooleanObject(leftVal != "" && rightVal != "")
-
-	case "||", "or":
-		return nativeBoolToBooleanObject(leftVal != "" || rightVal != "")
 
 	default:
 		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
 	}
 }
 
+// evalDestructuringLetStatement binds each value of a tuple to the name at
+// the same position, values bound to _ are discarded.
+func evalDestructuringLetStatement(node *ast.LetStatement, env *object.Environment) object.Object {
+	val := Eval(node.Value, env)
+	if isError(val) {
+		return val
+	}
+
+	values := []object.Object{val}
+	if tuple, ok := val.(*object.Tuple); ok {
+		values = tuple.Elements
+	}
+	if len(values) != len(node.Names) {
+		return newErrorAt(node.Token, "assignment mismatch: want %d values, got %d", len(node.Names), len(values))
+	}
+
+	for i, name := range node.Names {
+		if name.Value == "_" {
+			continue
+		}
+		if node.Mutable {
+			env.SetMutable(name.Value, values[i])
+		} else {
+			env.Set(name.Value, values[i])
+		}
+	}
+	return nil
+}
+
+func evalAssignExpression(node *ast.AssignExpression, env *object.Environment) object.Object {
+	var value object.Object
+	if node.Value != nil {
+		value = Eval(node.Value, env)
+		if isError(value) {
+			return value
+		}
+	}
+
+	if ident := ast.AssignedIdentifier(node.Target); ident != nil {
+		if _, ok := env.Get(ident.Value); !ok {
+			return newErrorAt(ident.Token, "identifier not found: %s", ident.Value)
+		}
+		if !env.IsMutable(ident.Value) {
+			return newErrorAt(ident.Token, "cannot assign to immutable variable: %s", ident.Value)
+		}
+	}
+
+	switch target := node.Target.(type) {
+	case *ast.Identifier:
+		current, _ := env.Get(target.Value)
+		newValue := evalAssignedValue(node.Operator, current, value)
+		if isError(newValue) {
+			return newValue
+		}
+		env.Assign(target.Value, newValue)
+		return assignResult(node.Operator, current, newValue)
+
+	case *ast.IndexExpression:
+		left := Eval(target.Left, env)
+		if isError(left) {
+			return left
+		}
+		index := Eval(target.Index, env)
+		if isError(index) {
+			return index
+		}
+		return evalIndexAssignment(node.Operator, left, index, value)
+
+	case *ast.FieldExpression:
+		left := Eval(target.Left, env)
+		if isError(left) {
+			return left
+		}
+		return evalFieldAssignment(node.Operator, left, target.Field.Value, value)
+
+	default:
+		return newError("cannot assign to %s", node.Target.String())
+	}
+}
+
+func evalIndexAssignment(operator string, left, index, value object.Object) object.Object {
+	switch left := left.(type) {
+	case *object.Array:
+		idx, ok := integerValue(index)
+		if !ok {
+			return newError("index operator not supported: %s[%s]", left.Type(), index.Type())
+		}
+		if idx < 0 || idx >= int64(len(left.Elements)) {
+			return newError("index out of range: %d", idx)
+		}
+		current := left.Elements[idx]
+		newValue := evalOperatorAssignment(operator, current, value)
+		if isError(newValue) {
+			return newValue
+		}
+		left.Elements[idx] = newValue
+		return assignResult(operator, current, newValue)
+
+	case *object.Hash:
+		key, ok := index.(object.Hashable)
+		if !ok {
+			return newError("unusable as hash key: %s", index.Type())
+		}
+		var current object.Object = NULL
+		if pair, ok := left.Pairs[key.HashKey()]; ok {
+			current = pair.Value
+		}
+		newValue := evalOperatorAssignment(operator, current, value)
+		if isError(newValue) {
+			return newValue
+		}
+		left.Pairs[key.HashKey()] = object.HashPair{Key: index, Value: newValue}
+		return assignResult(operator, current, newValue)
+
+	default:
+		return newError("index assignment not supported: %s", left.Type())
+	}
+}
+
+// evalOperatorAssignment computes the value stored by an assignment operator
+// from the current value of its target.
+func evalOperatorAssignment(operator string, current, value object.Object) object.Object {
+	switch operator {
+	case "=":
+		return value
+	case "++":
+		return evalInfixExpression("+", current, &object.Integer{Value: 1})
+	case "--":
+		return evalInfixExpression("-", current, &object.Integer{Value: 1})
+	default:
+		return evalInfixExpression(strings.TrimSuffix(operator, "="), current, value)
+	}
+}
+
+// evalAssignedValue computes the new value of a variable. Variables keep
+// their type: integers are converted to the numeric type of the variable
+// like a C assignment, any other change of type is an error.
+func evalAssignedValue(operator string, current, value object.Object) object.Object {
+	newValue := evalOperatorAssignment(operator, current, value)
+	switch {
+	case isError(newValue):
+		return newValue
+	case current == NULL || newValue.Type() == current.Type():
+		return newValue
+	case isInteger(newValue) && (isInteger(current) || current.Type() == object.FLOAT_OBJ):
+		return evalCastExpression(newValue, typeNameOf(current))
+	default:
+		return newError("cannot assign %s to variable of type %s", newValue.Type(), current.Type())
+	}
+}
+
+// assignResult returns the value of an assignment expression, postfix
+// increments and decrements evaluate to the value before the assignment.
+func assignResult(operator string, current, newValue object.Object) object.Object {
+	if operator == "++" || operator == "--" {
+		return current
+	}
+	return newValue
+}
+
 func evalIfExpression(ie *ast.IfExpression, env *object.Environment) object.Object {
 	condition := Eval(ie.Condition, env)
 	if isError(condition) {
@@ -339,25 +999,32 @@ func evalMatchExpression(ie *ast.MatchExpression, env *object.Environment) objec
 	if isError(condition) {
 		return condition
 	}
+	if u, ok := condition.(*object.Union); ok {
+		if err := checkUnionMatch(ie, u.Definition); err != nil {
+			return locate(err, ie.Token, env)
+		}
+	}
 
 	for _, match := range ie.MatchBlock.Cases {
-		if match.Condition != nil {
-			caseCondition := Eval(match.Condition, env)
-			if isError(caseCondition) {
-				return caseCondition
+		bindings := make(map[string]object.Object)
+		if !matchPattern(match.Pattern, condition, env, bindings) {
+			continue
+		}
+
+		caseEnv := object.NewEnclosedEnvironment(env)
+		for name, val := range bindings {
+			caseEnv.Set(name, val)
+		}
+		if match.Guard != nil {
+			guard := Eval(match.Guard, caseEnv)
+			if isError(guard) {
+				return guard
 			}
-			if caseCondition.Type() == condition.Type() {
-				if isEqual(condition, caseCondition) {
-					return Eval(match.Consequence, env)
-				}
-			} else if caseCondition.Type() == object.BOOLEAN_OBJ {
-				if isTruthy(caseCondition) {
-					return Eval(match.Consequence, env)
-				}
-			} else if isTruthy(caseCondition) {
-				return Eval(match.Consequence, env)
+			if !isTruthy(guard) {
+				continue
 			}
 		}
+		return Eval(match.Consequence, caseEnv)
 	}
 
 	if ie.Alternative != nil {
@@ -369,14 +1036,21 @@ func evalMatchExpression(ie *ast.MatchExpression, env *object.Environment) objec
 
 func evalBlockStatement(block *ast.BlockStatement, env *object.Environment) object.Object {
 	var result object.Object
+	var defers deferredCalls
 
 	for _, statement := range block.Statements {
+		if stmt, ok := statement.(*ast.DeferStatement); ok {
+			if result = defers.add(stmt, env); result != nil {
+				break
+			}
+			continue
+		}
 		result = Eval(statement, env)
-		if result != nil && (result.Type() == object.RETURN_VALUE_OBJ || result.Type() == object.ERROR_OBJ) {
-			return result
+		if result != nil && isControlFlow(result) {
+			break
 		}
 	}
-	return result
+	return defers.run(result, env)
 }
 
 func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object {
@@ -389,9 +1063,7 @@ func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object
 		return builtin
 	}
 
-	msg := fmt.Sprintf("[%d:%d] identifier not found: %s", node.Token.Position().Line(), node.Token.Position().Column(), node.Value)
-
-	return newError(msg)
+	return newErrorAt(node.Token, "identifier not found: %s", node.Value)
 }
 
 func evalExpressions(exps []ast.Expression, env *object.Environment) []object.Object {
@@ -409,9 +1081,9 @@ func evalExpressions(exps []ast.Expression, env *object.Environment) []object.Ob
 
 func evalIndexExpression(left, index object.Object) object.Object {
 	switch {
-	case left.Type() == object.ARRAY_OBJ && index.Type() == object.INTEGER_OBJ:
+	case left.Type() == object.ARRAY_OBJ && isInteger(index):
 		return evalArrayIndexExpression(left, index)
-	case left.Type() == object.STRING_OBJ && index.Type() == object.INTEGER_OBJ:
+	case left.Type() == object.STRING_OBJ && isInteger(index):
 		return evalStringIndexExpression(left, index)
 	case left.Type() == object.HASH_OBJ:
 		return evalHashIndexExpression(left, index)
@@ -423,7 +1095,7 @@ func evalIndexExpression(left, index object.Object) object.Object {
 
 func evalArrayIndexExpression(array, index object.Object) object.Object {
 	arrayObject := array.(*object.Array)
-	idx := index.(*object.Integer).Value
+	idx, _ := integerValue(index)
 	max := int64(len(arrayObject.Elements) - 1)
 
 	if idx < 0 || idx > max {
@@ -435,7 +1107,7 @@ func evalArrayIndexExpression(array, index object.Object) object.Object {
 
 func evalStringIndexExpression(str, index object.Object) object.Object {
 	strObject := str.(*object.String)
-	idx := index.(*object.Integer).Value
+	idx, _ := integerValue(index)
 	max := int64(len(strObject.Value) - 1)
 
 	if idx < 0 || idx > max {
@@ -542,71 +1214,176 @@ func evalLoopStatement(loop *ast.LoopStatement, env *object.Environment) object.
 			}
 		}
 
-		shouldBreak := false
-		shouldContinue := false
-		for _, statement := range loop.Body.Statements {
-			stmtResult := Eval(statement, env)
-			if stmtResult != nil {
-				if isError(stmtResult) {
-					return stmtResult
-				}
-				if stmtResult.Type() == object.BREAK_OBJ {
-					shouldBreak = true
-					break
-				}
-				if stmtResult.Type() == object.CONTINUE_OBJ {
-					shouldContinue = true
-					break
-				}
-				if stmtResult.Type() == object.RETURN_VALUE_OBJ {
-					return stmtResult
-				}
-			}
-		}
-		if shouldBreak {
-			break
-		}
-		if shouldContinue {
+		result := evalLoopBody(loop.Body, env)
+		if result == nil || continuesLoop(result, loop.Label) {
 			continue
 		}
+		return loopResult(result, loop.Label)
 	}
 	return VOID
 }
 
-func applyFunction(fn object.Object, args []object.Object) object.Object {
+func evalBreakStatement(node *ast.BreakStatement, env *object.Environment) object.Object {
+	brk := &object.Break{}
+	if node.Label != nil {
+		brk.Label = node.Label.Value
+	}
+	if node.Value != nil {
+		brk.Value = Eval(node.Value, env)
+		if isError(brk.Value) {
+			return brk.Value
+		}
+	}
+	return brk
+}
 
-	switch fn := fn.(type) {
-	case *object.Function:
-		extendedEnv := extendFunctionEnv(fn, args)
-		evaluated := Eval(fn.Body, extendedEnv)
-		return unwrapReturnValue(evaluated)
+// targetsLoop reports whether a break or continue with the given label
+// applies to the loop labeled loopLabel.
+func targetsLoop(label string, loopLabel *ast.Identifier) bool {
+	return label == "" || (loopLabel != nil && loopLabel.Value == label)
+}
 
-	case *object.Builtin:
-		return fn.Fn(args...)
+func continuesLoop(result object.Object, loopLabel *ast.Identifier) bool {
+	cont, ok := result.(*object.Continue)
+	return ok && targetsLoop(cont.Label, loopLabel)
+}
 
-	default:
-		return newError("not a function: %s", fn.Type())
+// loopResult returns what a loop interrupted by result evaluates to: the
+// value of a break targeting it, or result itself for anything that must
+// propagate further up, such as a return or a break for an outer loop.
+func loopResult(result object.Object, loopLabel *ast.Identifier) object.Object {
+	brk, ok := result.(*object.Break)
+	if !ok || !targetsLoop(brk.Label, loopLabel) {
+		return result
 	}
+	if brk.Value == nil {
+		return VOID
+	}
+	return brk.Value
 }
 
-func extendFunctionEnv(fn *object.Function, args []object.Object) *object.Environment {
-	env := object.NewEnclosedEnvironment(fn.Env)
+func evalForStatement(loop *ast.ForStatement, env *object.Environment) object.Object {
+	iterable := Eval(loop.Iterable, env)
+	if isError(iterable) {
+		return iterable
+	}
+
+	variable, ok := loop.Variable.(*ast.Identifier)
+	if !ok {
+		return newError("invalid loop variable: %s", loop.Variable.String())
+	}
+	var value *ast.Identifier
+	if loop.Value != nil {
+		if value, ok = loop.Value.(*ast.Identifier); !ok {
+			return newError("invalid loop variable: %s", loop.Value.String())
+		}
+	}
+
+	result := iterate(iterable, func(key, elem object.Object) object.Object {
+		loopEnv := object.NewEnclosedEnvironment(env)
+		if value != nil {
+			bindLoopVariable(loopEnv, variable, key)
+			bindLoopVariable(loopEnv, value, elem)
+		} else if iterable.Type() == object.HASH_OBJ {
+			bindLoopVariable(loopEnv, variable, key)
+		} else {
+			bindLoopVariable(loopEnv, variable, elem)
+		}
+
+		result := evalLoopBody(loop.Body, loopEnv)
+		if result == nil || continuesLoop(result, loop.Label) {
+			return nil
+		}
+		return result
+	})
+	if result == nil {
+		return VOID
+	}
+	return loopResult(result, loop.Label)
+}
 
-	for paramIdx, param := range fn.Parameters {
-		env.Set(param.Value, args[paramIdx])
+func bindLoopVariable(env *object.Environment, ident *ast.Identifier, val object.Object) {
+	if ident.Value != "_" {
+		env.Set(ident.Value, val)
 	}
+}
 
-	return env
+// evalLoopBody runs a single iteration of a loop body. It returns the object
+// that interrupted the iteration (break, continue, return value or error), or
+// nil if every statement ran to completion.
+func evalLoopBody(body *ast.BlockStatement, env *object.Environment) object.Object {
+	var result object.Object
+	var defers deferredCalls
+
+	for _, statement := range body.Statements {
+		if stmt, ok := statement.(*ast.DeferStatement); ok {
+			if result = defers.add(stmt, env); result != nil {
+				break
+			}
+			continue
+		}
+		if result = Eval(statement, env); result != nil && isControlFlow(result) {
+			break
+		}
+		result = nil
+	}
+	return defers.run(result, env)
 }
 
-func unwrapReturnValue(obj object.Object) object.Object {
-	if returnValue, ok := obj.(*object.ReturnValue); ok {
-		return returnValue.Value
+// iterate calls fn with each key/element pair of iterable: index and element
+// for arrays, index and character for strings, key and value for hashes and
+// index and value for ranges. Iteration stops as soon as fn returns a non-nil
+// object, which is then returned to the caller.
+func iterate(iterable object.Object, fn func(key, elem object.Object) object.Object) object.Object {
+	switch iterable := iterable.(type) {
+	case *object.Array:
+		for i, elem := range iterable.Elements {
+			if result := fn(&object.Integer{Value: int64(i)}, elem); result != nil {
+				return result
+			}
+		}
+
+	case *object.String:
+		for i, r := range []rune(iterable.Value) {
+			if result := fn(&object.Integer{Value: int64(i)}, &object.String{Value: string(r)}); result != nil {
+				return result
+			}
+		}
+
+	case *object.Hash:
+		// hash pairs have no defined order, sort them so iteration is stable
+		pairs := make([]object.HashPair, 0, len(iterable.Pairs))
+		for _, pair := range iterable.Pairs {
+			pairs = append(pairs, pair)
+		}
+		sort.Slice(pairs, func(i, j int) bool {
+			return pairs[i].Key.Inspect() < pairs[j].Key.Inspect()
+		})
+		for _, pair := range pairs {
+			if result := fn(pair.Key, pair.Value); result != nil {
+				return result
+			}
+		}
+
+	case *object.Range:
+		var idx int64
+		for i := iterable.Start; (iterable.Step > 0 && i < iterable.End) || (iterable.Step < 0 && i > iterable.End); i += iterable.Step {
+			if result := fn(&object.Integer{Value: idx}, &object.Integer{Value: i}); result != nil {
+				return result
+			}
+			idx++
+		}
+
+	default:
+		return newError("not iterable: %s", iterable.Type())
 	}
-	return obj
+	return nil
 }
 
 func isTruthy(obj object.Object) bool {
+	if named, ok := obj.(*object.Named); ok {
+		return isTruthy(named.Value)
+	}
 	switch obj {
 	case NULL:
 		return false
@@ -629,9 +1406,21 @@ func isEqual(obj1 object.Object, obj2 object.Object) bool {
 	return true
 }
 
+// isControlFlow reports whether obj interrupts the evaluation of a block.
+func isControlFlow(obj object.Object) bool {
+	switch obj.Type() {
+	case object.RETURN_VALUE_OBJ, object.ERROR_OBJ, object.BREAK_OBJ, object.CONTINUE_OBJ:
+		return true
+	}
+	return false
+}
+
+// isError reports whether obj interrupts the evaluation of the expression
+// producing it: a runtime error, or a value returned from the enclosing
+// function before the expression completed, as done by the ? operator.
 func isError(obj object.Object) bool {
 	if obj != nil {
-		return obj.Type() == object.ERROR_OBJ
+		return obj.Type() == object.ERROR_OBJ || obj.Type() == object.RETURN_VALUE_OBJ
 	}
 	return false
 }
@@ -639,3 +1428,36 @@ func isError(obj object.Object) bool {
 func newError(format string, a ...interface{}) *object.Error {
 	return &object.Error{Message: fmt.Sprintf(format, a...)}
 }
+
+// newErrorAt returns an error located at token.
+func newErrorAt(token lexer.Token, format string, a ...interface{}) *object.Error {
+	err := newError(format, a...)
+	err.Span = tokenSpan(token)
+	return err
+}
+
+func tokenSpan(token lexer.Token) *object.Span {
+	pos := token.Position()
+	return &object.Span{
+		Line:      pos.Line(),
+		Column:    pos.Column(),
+		EndLine:   pos.Line(),
+		EndColumn: pos.Column() + utf8.RuneCountInString(token.Literal),
+	}
+}
+
+// locate locates an error raised while evaluating the code at token in env,
+// unless code nested in it already did.
+func locate(obj object.Object, token lexer.Token, env *object.Environment) object.Object {
+	err, ok := obj.(*object.Error)
+	if !ok {
+		return obj
+	}
+	if err.Span == nil {
+		err.Span = tokenSpan(token)
+	}
+	if err.File == "" {
+		err.File = env.File()
+	}
+	return err
+}
@@ -1,11 +1,16 @@
 package evaluator_test
 
 import (
+	"bufio"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/poolpOrg/julu/ast"
 	"github.com/poolpOrg/julu/evaluator"
+	"github.com/poolpOrg/julu/lexer"
 	"github.com/poolpOrg/julu/object"
+	"github.com/poolpOrg/julu/parser"
 )
 
 func TestEvalIntegerExpression(t *testing.T) {
@@ -131,6 +136,974 @@ func TestEvalInfixExpression(t *testing.T) {
 	}
 }
 
+func TestEvalLogicalExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{input: "true && false", expected: false},
+		{input: "true && true", expected: true},
+		{input: "false || true", expected: true},
+		{input: "false or false", expected: false},
+		{input: "true and 1", expected: true},
+		{input: "null || 0", expected: true},
+		{input: "null && true", expected: false},
+		{input: `"" || false`, expected: true},
+		{input: "1 > 2 || 2 > 1 && 3 > 2", expected: true},
+		{input: "let x = null; x != null && x > 0", expected: false},
+		{input: "false && undefined", expected: false},
+		{input: "true || 1 / 0", expected: true},
+		{input: "!null", expected: true},
+		{input: "not 5", expected: false},
+		{input: "not (1 > 2)", expected: true},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testBooleanObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestEvalFloatExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{input: "1.5 + 2.25", expected: 3.75},
+		{input: "1.5 - 2.5", expected: -1.0},
+		{input: "1.5 * 2", expected: 3.0},
+		{input: "7 / 2.0", expected: 3.5},
+		{input: "7.5 % 2", expected: 1.5},
+		{input: "-2.5", expected: -2.5},
+		{input: "1.5 < 2", expected: true},
+		{input: "2 >= 2.5", expected: false},
+		{input: "2 == 2.0", expected: true},
+		{input: "1.5 != 1.5", expected: false},
+		{input: "7 / 2", expected: 3},
+		{input: "1.5 / 0", expected: "[1:5] division by zero: 1.500000 / 0.000000"},
+		{input: "7 / 0", expected: "[1:3] division by zero: 7 / 0"},
+		{input: "7 % 0", expected: "[1:3] division by zero: 7 % 0"},
+		{input: "1.5 & 1.5", expected: "[1:5] unknown operator: FLOAT & FLOAT"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case float64:
+			testFloatObject(t, evaluated, expected)
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestEvalCastExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{input: "300 as uint8", expected: sizedInteger{object.UINT8_OBJ, "44"}},
+		{input: "-1 as uint16", expected: sizedInteger{object.UINT16_OBJ, "65535"}},
+		{input: "128 as int8", expected: sizedInteger{object.INT8_OBJ, "-128"}},
+		{input: "70000 as int16", expected: sizedInteger{object.INT16_OBJ, "4464"}},
+		{input: "3.9 as int", expected: 3},
+		{input: "-3.9 as int8", expected: sizedInteger{object.INT8_OBJ, "-3"}},
+		{input: "5 as float / 2", expected: 2.5},
+		{input: "0.1 as float32", expected: float64(float32(0.1))},
+		{input: "true as int", expected: 1},
+		{input: "false as uint8", expected: sizedInteger{object.UINT8_OBJ, "0"}},
+		{input: "2 as bool", expected: true},
+		{input: "0.0 as bool", expected: false},
+		{input: "'a' as int", expected: 97},
+		{input: "('a' as int + 1) as char == 'b'", expected: true},
+		{input: "-1 as uint64", expected: sizedInteger{object.UINT64_OBJ, "18446744073709551615"}},
+		{input: "(-1 as int8) as uint32", expected: sizedInteger{object.UINT32_OBJ, "4294967295"}},
+		{input: "(255 as uint8) as int8", expected: sizedInteger{object.INT8_OBJ, "-1"}},
+		{input: "18446744073709551615 as float", expected: 18446744073709551615.0},
+		{input: "10000000000000000000.0 as uint64", expected: sizedInteger{object.UINT64_OBJ, "10000000000000000000"}},
+		{input: "300.0 as int8", expected: "[1:7] cannot convert 300.000000 to int8: out of range"},
+		{input: "-1.0 as uint32", expected: "[1:6] cannot convert -1.000000 to uint32: out of range"},
+		{input: "-1 as char", expected: "[1:4] cannot convert -1 to char: out of range"},
+		{input: "\"1\" as int", expected: "[1:5] cannot convert STRING to int"},
+		{input: "1 as string", expected: "[1:3] cannot convert INTEGER to string"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case sizedInteger:
+			testSizedIntegerObject(t, evaluated, expected)
+		case float64:
+			testFloatObject(t, evaluated, expected)
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestEvalSizedIntegerExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{input: "let x: uint8 = 300; x", expected: sizedInteger{object.UINT8_OBJ, "44"}},
+		{input: "let x: uint8 = 250; x + 10", expected: sizedInteger{object.UINT8_OBJ, "4"}},
+		{input: "let x: int8 = 127; x + 1", expected: sizedInteger{object.INT8_OBJ, "-128"}},
+		{input: "let x: uint16 = 0; x - 1", expected: sizedInteger{object.UINT16_OBJ, "65535"}},
+		{input: "let x: int8 = -128; x / -1", expected: sizedInteger{object.INT8_OBJ, "-128"}},
+		{input: "let x: int32 = -7; x / 2", expected: sizedInteger{object.INT32_OBJ, "-3"}},
+		{input: "let x: uint32 = 4294967289; x / 2", expected: sizedInteger{object.UINT32_OBJ, "2147483644"}},
+		{input: "let x: int8 = -8; x >> 1", expected: sizedInteger{object.INT8_OBJ, "-4"}},
+		{input: "let x: uint8 = 248; x >> 1", expected: sizedInteger{object.UINT8_OBJ, "124"}},
+		{input: "let x: uint8 = 1; x << 9", expected: sizedInteger{object.UINT8_OBJ, "0"}},
+		{input: "let x: uint64 = 18446744073709551615; x", expected: sizedInteger{object.UINT64_OBJ, "18446744073709551615"}},
+		{input: "18446744073709551615", expected: sizedInteger{object.UINT64_OBJ, "18446744073709551615"}},
+		{input: "let x: uint64 = 9223372036854775808; x > 1", expected: true},
+		{input: "let x: int64 = -1; let y: uint64 = 1; (x as uint64) > y", expected: true},
+		{input: "let x: int8 = -1; x < 0", expected: true},
+		{input: "let x: uint8 = 255; x == 255", expected: true},
+		{input: "let x: uint8 = 2; x * 1.5", expected: 3.0},
+		{input: "let x: uint8 = 2; [1, 2, 3][x]", expected: 3},
+		{input: "let x: int16 = 1; -x", expected: sizedInteger{object.INT16_OBJ, "-1"}},
+		{input: "let x: float = 1; x", expected: 1.0},
+		{input: "let x: uint8 = 1; let y: uint16 = 1; x + y", expected: "[1:40] type mismatch: UINT8 + UINT16"},
+		{input: "let x: uint8 = 1; x / 0", expected: "[1:21] division by zero: 1 / 0"},
+		{input: "let x: uint8 = 1; x >> -1", expected: "[1:21] negative shift count: -1"},
+		{input: "1 << -1", expected: "[1:3] negative shift count: -1"},
+		{input: "let x: int = 1.5", expected: "[1:1] cannot use FLOAT as int"},
+		{input: "let x: uint8 = \"a\"", expected: "[1:1] cannot use STRING as uint8"},
+		{input: "let x: foo = 1", expected: "[1:1] unknown type: foo"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case sizedInteger:
+			testSizedIntegerObject(t, evaluated, expected)
+		case float64:
+			testFloatObject(t, evaluated, expected)
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestEvalBitwiseAndPowerExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{input: "2 ** 10", expected: 1024},
+		{input: "2 ** 3 ** 2", expected: 512},
+		{input: "-2 ** 2", expected: -4},
+		{input: "3 ** 0", expected: 1},
+		{input: "2.0 ** 0.5 == 2.0 ** 0.5", expected: true},
+		{input: "4 ** 0.5", expected: 2.0},
+		{input: "2 ** -1", expected: "[1:3] negative exponent: 2 ** -1"},
+		{input: "let x: uint8 = 2; x ** 9", expected: sizedInteger{object.UINT8_OBJ, "0"}},
+		{input: "let x: int8 = 3; x ** 5", expected: sizedInteger{object.INT8_OBJ, "-13"}},
+		{input: "~0", expected: -1},
+		{input: "~5", expected: -6},
+		{input: "let x: uint8 = 0x0f; ~x", expected: sizedInteger{object.UINT8_OBJ, "240"}},
+		{input: "let x: uint32 = 0; ~x", expected: sizedInteger{object.UINT32_OBJ, "4294967295"}},
+		{input: "~1.5", expected: "[1:1] unknown operator: ~FLOAT"},
+		{input: "let x: uint8 = 0x81; x <<< 1", expected: sizedInteger{object.UINT8_OBJ, "3"}},
+		{input: "let x: uint8 = 0x81; x >>> 1", expected: sizedInteger{object.UINT8_OBJ, "192"}},
+		{input: "let x: uint8 = 0x81; x <<< 9", expected: sizedInteger{object.UINT8_OBJ, "3"}},
+		{input: "let x: uint16 = 0x1234; x <<< 4", expected: sizedInteger{object.UINT16_OBJ, "9025"}},
+		{input: "let x: uint32 = 0x80000000; x <<< 1", expected: sizedInteger{object.UINT32_OBJ, "1"}},
+		{input: "let x: int8 = -128; x >>> 7", expected: sizedInteger{object.INT8_OBJ, "1"}},
+		{input: "let x: uint64 = 1; x >>> 1", expected: sizedInteger{object.UINT64_OBJ, "9223372036854775808"}},
+		{input: "1 >>> 1", expected: -9223372036854775808},
+		{input: "(-9223372036854775807 - 1) <<< 1", expected: 1},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case sizedInteger:
+			testSizedIntegerObject(t, evaluated, expected)
+		case float64:
+			testFloatObject(t, evaluated, expected)
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestEvalAssignExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{input: "let mut x = 1; x = 5; x", expected: 5},
+		{input: "let mut x = 1; x += 2; x", expected: 3},
+		{input: "let mut x = 7; x -= 2; x *= 3; x /= 5; x", expected: 3},
+		{input: "let mut x = 7; x %= 4; x", expected: 3},
+		{input: "let mut x = 6; x &= 3; x |= 8; x ^= 1; x", expected: 11},
+		{input: "let mut x = 1; x <<= 4; x >>= 1; x", expected: 8},
+		{input: "let mut x = 1; x++", expected: 1},
+		{input: "let mut x = 1; x++; x", expected: 2},
+		{input: "let mut x = 1; x--; x", expected: 0},
+		{input: "let mut x = 1; x = 2 + 3", expected: 5},
+		{input: "let mut a = 1; let mut b = 2; a = b = 3; a + b", expected: 6},
+		{input: "let mut x = 0; while x < 42 { x++ }; x", expected: 42},
+		{input: "let mut x = 0; fn f { x = 5 }; f(); x", expected: 5},
+		{input: "let mut x = 0; for i in range(5) { x += i }; x", expected: 10},
+		{input: "let mut x = 0; fn f { let mut x = 1; x = 2 }; f(); x", expected: 0},
+		{input: "fn counter { let mut n = 0; return fn() { n++; return n } }; let c = counter(); c(); c()", expected: 2},
+		{input: "let mut a = [1, 2, 3]; a[1] = 5; a[1]", expected: 5},
+		{input: "let mut a = [1, 2, 3]; a[2] *= 4; a[2]", expected: 12},
+		{input: "let mut a = [[1], [2]]; a[1][0] = 7; a[1][0]", expected: 7},
+		{input: "let mut h = {\"k\": 1}; h[\"k\"] += 1; h[\"k\"]", expected: 2},
+		{input: "let mut h = {\"k\": 1}; h[\"n\"] = 3; h[\"n\"]", expected: 3},
+		{input: "let mut x: uint8 = 250; x += 10; x", expected: sizedInteger{object.UINT8_OBJ, "4"}},
+		{input: "let mut x: uint8 = 0; x = 300; x", expected: sizedInteger{object.UINT8_OBJ, "44"}},
+		{input: "let mut x = 1.5; x = 2; x", expected: 2.0},
+		{input: "x = 1", expected: "[1:1] identifier not found: x"},
+		{input: "let mut x = 1; x = \"s\"", expected: "[1:18] cannot assign STRING to variable of type INTEGER"},
+		{input: "let mut x = 1; x += 0.5", expected: "[1:18] cannot assign FLOAT to variable of type INTEGER"},
+		{input: "let x = 1; x = 2", expected: "[1:12] cannot assign to immutable variable: x"},
+		{input: "let x = 1; x++", expected: "[1:12] cannot assign to immutable variable: x"},
+		{input: "let a = [1]; a[0] = 2", expected: "[1:14] cannot assign to immutable variable: a"},
+		{input: "let mut x = 1; let x = 2; x = 3", expected: "[1:27] cannot assign to immutable variable: x"},
+		{input: "fn f(n) { n = 1 }; f(0)", expected: "[1:11] cannot assign to immutable variable: n"},
+		{input: "for i in [1] { i = 2 }", expected: "[1:16] cannot assign to immutable variable: i"},
+		{input: "let mut a = [1]; a[3] = 1", expected: "[1:23] index out of range: 3"},
+		{input: "let mut s = \"abc\"; s[0] = \"x\"", expected: "[1:25] index assignment not supported: STRING"},
+		{input: "let mut h = {}; h[\"k\"] += 1", expected: "[1:24] type mismatch: NULL + INTEGER"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case sizedInteger:
+			testSizedIntegerObject(t, evaluated, expected)
+		case float64:
+			testFloatObject(t, evaluated, expected)
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestEvalForStatement(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{input: "fn f { for x in [1, 2, 3, 4] { if x == 3 { return x } } return 0 }; f()", expected: 3},
+		{input: "fn f { for i, x in [5, 6, 7] { if x == 7 { return i } } return 0 }; f()", expected: 2},
+		{input: "fn f { for c in \"abc\" { if c == \"b\" { return true } } return false }; f()", expected: true},
+		{input: "fn f { for k in {\"a\": 1} { return k == \"a\" } return false }; f()", expected: true},
+		{input: "fn f { for k, v in {\"a\": 1, \"b\": 2} { if k == \"b\" { return v } } return 0 }; f()", expected: 2},
+		{input: "fn f { for x in range(5) { if x < 3 { continue } return x } return 0 }; f()", expected: 3},
+		{input: "fn f { for x in range(10, 0, -4) { if x < 5 { break } } return 1 }; f()", expected: 1},
+		{input: "for x in 5 => x", expected: "[1:1] not iterable: INTEGER"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestEvalLabeledLoop(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{input: "let mut i = 0; let x = loop { i += 1; if i == 3 { break i * 10 } }; x", expected: 30},
+		{input: "let mut n = 0; outer: for i in range(5) { for j in range(5) { if j > i { continue outer } if i == 3 { break outer } n += 1 } }; n", expected: 6},
+		{input: "let found = outer: for i in range(1, 10) { for j in range(1, 10) { if i * j == 42 { break outer [i, j] } } }; found[0] * 100 + found[1]", expected: 607},
+		{input: "let mut i = 0; let mut n = 0; outer: while i < 3 { i += 1; loop { n += 1; continue outer } }; n", expected: 3},
+		{input: "let mut i = 0; outer: until i == 5 { i += 1; loop { break outer } }; i", expected: 1},
+		{input: "let mut n = 0; for i in range(3) { loop { break } n += 1 }; n", expected: 3},
+		{input: "fn f { for x in [1, 2, 3] { loop { return x * 2 } } }; f()", expected: 2},
+		{input: "loop { break undefined }", expected: "[1:14] identifier not found: undefined"},
+		{input: "fn f { break }; for i in range(5) { f() }", expected: "[1:38] break outside a loop in f"},
+		{input: "fn f { continue }; fn g { f(); 1 }; g()", expected: "[1:28] continue outside a loop in f"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestEvalFunctionSignature(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{input: "fn add(x: int, y: int) -> int { return x + y }; add(1, 2)", expected: 3},
+		{input: "fn add(x, y) => x + y; add(1, 2)", expected: 3},
+		{input: "fn f(x: uint8) => x; f(300)", expected: sizedInteger{object.UINT8_OBJ, "44"}},
+		{input: "fn f(x: int) -> uint8 => x; f(300)", expected: sizedInteger{object.UINT8_OBJ, "44"}},
+		{input: "fn add(x, y) => x + y\nadd(1)", expected: "[2:4] wrong number of arguments for add: want 2, got 1"},
+		{input: "fn add(x, y) => x + y; add(1, 2, 3)", expected: "[1:27] wrong number of arguments for add: want 2, got 3"},
+		{input: "let f = fn(x) => x; f()", expected: "[1:22] wrong number of arguments for anonymous function: want 1, got 0"},
+		{input: `fn f(x: int) => x; f("a")`, expected: "[1:21] argument x of f: cannot use STRING as int"},
+		{input: `fn f(x: point) => x; f(1)`, expected: "[1:23] argument x of f: unknown type: point"},
+		{input: `fn f(x) -> string => x; f(1)`, expected: "[1:26] return value of f: cannot use INTEGER as string"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case sizedInteger:
+			testSizedIntegerObject(t, evaluated, expected)
+		case string:
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestEvalFunctionArguments(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{input: "fn f(a, b = 2) => a * 10 + b; f(1)", expected: 12},
+		{input: "fn f(a, b = 2) => a * 10 + b; f(1, 3)", expected: 13},
+		{input: "fn f(a, b = a * 2) => a * 10 + b; f(3)", expected: 36},
+		{input: "fn f(a, b = 2, c = 3) => a * 100 + b * 10 + c; f(1, c: 5)", expected: 125},
+		{input: "fn f(a, b) => a * 10 + b; f(b: 1, a: 2)", expected: 21},
+		{input: "fn f(a, ...rest) => len(rest); f(1, 2, 3, 4)", expected: 3},
+		{input: "fn f(a, ...rest) => len(rest); f(1)", expected: 0},
+		{input: "fn f(...rest: uint8) => rest[0]; f(300)", expected: sizedInteger{object.UINT8_OBJ, "44"}},
+		{input: "fn f(a, b, c) => a * 100 + b * 10 + c; let xs = [1, 2, 3]; f(...xs)", expected: 123},
+		{input: "fn f(a, b, c) => a * 100 + b * 10 + c; f(1, ...[2, 3])", expected: 123},
+		{input: "fn f(a, ...rest) => rest[1]; f(...[1, 2, 3])", expected: 3},
+		{input: "fn g { return 1, 2 }; fn f(a, b) => a + b; f(...g())", expected: 3},
+		{input: "len(value: [1, 2])", expected: 2},
+		{input: "len(...[[1, 2, 3]])", expected: 3},
+		{input: "fn f(a, b = 2) => a; f()", expected: "[1:23] wrong number of arguments for f: want 1 to 2, got 0"},
+		{input: "fn f(a, ...rest) => a; f()", expected: "[1:25] wrong number of arguments for f: want at least 1, got 0"},
+		{input: "fn f(a, b) => a; f(1, 2, 3)", expected: "[1:19] wrong number of arguments for f: want 2, got 3"},
+		{input: "fn f(a, b) => a; f(1, c: 2)", expected: "[1:19] unknown parameter c for f"},
+		{input: "fn f(a, b) => a; f(1, a: 2)", expected: "[1:19] argument a of f given twice"},
+		{input: "fn f(a, b) => a; f(b: 2)", expected: "[1:19] missing argument a for f"},
+		{input: "fn f(a) => a; f(...1)", expected: "[1:17] cannot spread INTEGER into arguments"},
+		{input: `println(value: "a")`, expected: "[1:8] println does not accept named arguments"},
+		{input: "len(x: 1)", expected: "[1:4] unknown parameter x for len"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case sizedInteger:
+			testSizedIntegerObject(t, evaluated, expected)
+		case string:
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestEvalMain(t *testing.T) {
+	tests := []struct {
+		input    string
+		args     []string
+		expected interface{}
+	}{
+		{input: "fn main => 3", args: []string{"a"}, expected: 3},
+		{input: "fn main(args: [string]) => len(args)", args: []string{"a", "b"}, expected: 2},
+		{input: "fn main(args) => len(args)", expected: 0},
+		{input: `fn main(args: [string]) => args[1] == "b"`, args: []string{"a", "b"}, expected: true},
+		{input: `fn main => getenv("JULU_TEST") == "value"`, expected: true},
+		{input: `fn main => getenv("JULU_TEST_UNSET") == null`, expected: true},
+		{input: `fn main => environ()["JULU_TEST"] == "value"`, expected: true},
+		{input: "fn main(args: [int]) => 0", args: []string{"a"}, expected: "argument args of main: cannot use STRING as int in element 0 of [int]"},
+	}
+
+	t.Setenv("JULU_TEST", "value")
+	for _, tt := range tests {
+		l := lexer.New(bufio.NewReader(strings.NewReader(tt.input)))
+		p := parser.New(l)
+		program := p.Parse()
+		env := object.NewEnvironment()
+		evaluator.Eval(program, env)
+
+		main, ok := env.Get("main")
+		if !ok {
+			t.Fatalf("main is not defined")
+		}
+
+		evaluated := evaluator.EvalMain(main, tt.args)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestEvalMultipleReturnValues(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{input: "fn f -> (int, int) { return 1, 2 }; let a, b = f(); a * 10 + b", expected: 12},
+		{input: "fn f { return 1, 2 }; let _, b = f(); b", expected: 2},
+		{input: "let a, b, c = 1, 2, 3; a + b + c", expected: 6},
+		{input: `fn div(a, b) { if b == 0 { return 0, "division by zero" } return a / b, null }; let q, err = div(6, 3); q`, expected: 2},
+		{input: `fn div(a, b) { if b == 0 { return 0, "division by zero" } return a / b, null }; let q, err = div(6, 0); err == "division by zero"`, expected: true},
+		{input: "fn f -> (uint8, int) { return 300, 1 }; let a, _ = f(); a", expected: sizedInteger{object.UINT8_OBJ, "44"}},
+		{input: `fn f -> (int, string) { return 1, null }; let _, err = f(); err == null`, expected: true},
+		{input: "fn f -> (int, int) { return 1, null }; f()", expected: "[1:41] return value of f: cannot use NULL as int"},
+		{input: "let mut a, b = 1, 2; a = 5; a", expected: 5},
+		{input: "let a, b = 1, 2; a = 5", expected: "[1:18] cannot assign to immutable variable: a"},
+		{input: "fn f -> (int, int) { return 1 }; f()", expected: "[1:35] wrong number of return values for f: want 2, got 1"},
+		{input: "fn f -> int { return 1, 2 }; f()", expected: "[1:31] wrong number of return values for f: want 1, got 2"},
+		{input: `fn f -> (int, int) { return 1, "a" }; f()`, expected: "[1:40] return value of f: cannot use STRING as int"},
+		{input: "fn f { return 1, 2, 3 }; let a, b = f()", expected: "[1:26] assignment mismatch: want 2 values, got 3"},
+		{input: "let a, b = 1", expected: "[1:1] assignment mismatch: want 2 values, got 1"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case sizedInteger:
+			testSizedIntegerObject(t, evaluated, expected)
+		case string:
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestEvalCallDepth(t *testing.T) {
+	defer func(depth int) { evaluator.MaxCallDepth = depth }(evaluator.MaxCallDepth)
+	evaluator.MaxCallDepth = 100
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{input: "fn f(n) { if n == 0 { return 0 } return 1 + f(n - 1) }; f(99)", expected: 99},
+		{input: "fn f(n) { if n == 0 { return 0 } return 1 + f(n - 1) }; f(100)", expected: "[1:46] stack overflow: maximum call depth of 100 exceeded: f [1:58] -> f [1:46] (100 times)"},
+		{input: "fn f(n) => g(n); fn g(n) => 1 + f(n); f(1)", expected: "[1:34] stack overflow: maximum call depth of 100 exceeded: g [1:13] (100 times) -> f [1:34]"},
+		{input: "fn f(n) => 1 + g(n); fn g(n) => 1 + f(n); f(1)", expected: "[1:38] stack overflow: maximum call depth of 100 exceeded: ... -> g [1:17] -> f [1:38] -> g [1:17] -> f [1:38] -> g [1:17] -> f [1:38] -> g [1:17] -> f [1:38]"},
+		{input: "fn sum(n, acc) { if n == 0 { return acc } return sum(n - 1, acc + n) }; sum(100000, 0)", expected: 5000050000},
+		{input: "fn even(n) => if n == 0 => true else => odd(n - 1); fn odd(n) => if n == 0 => false else => even(n - 1); even(10001)", expected: false},
+		{input: "fn f(n) -> uint8 { if n == 0 { return 300 } return f(n - 1) }; f(1000)", expected: sizedInteger{object.UINT8_OBJ, "44"}},
+		{input: "fn f(n) -> int { if n == 0 { return \"a\" } return g(n - 1) }; fn g(n) => f(n); f(1000)", expected: "[1:80] return value of f: cannot use STRING as int"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case sizedInteger:
+			testSizedIntegerObject(t, evaluated, expected)
+		case string:
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestEvalBuiltinCallbacks(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{input: "let ys = map([1, 2, 3], fn(x) => x * 2); ys[0] + ys[1] * 10 + ys[2] * 100", expected: 642},
+		{input: "len(filter(range(10), fn(x) => x % 3 == 0))", expected: 4},
+		{input: "reduce([1, 2, 3], fn(acc, x) => acc + x)", expected: 6},
+		{input: "reduce([], fn(acc, x) => acc + x, 10)", expected: 10},
+		{input: "let s = sort([3, 1, 2]); s[0] * 100 + s[1] * 10 + s[2]", expected: 123},
+		{input: "let s = sort([3, 1, 2], fn(a, b) => a > b); s[0] * 100 + s[1] * 10 + s[2]", expected: 321},
+		{input: "let s = sort([[2, 1], [1, 2], [2, 3]], fn(a, b) => a[0] < b[0]); s[1][1] * 10 + s[2][1]", expected: 13},
+		{input: "any([1, 2, 3], fn(x) => x > 2)", expected: true},
+		{input: "all([1, 2, 3], fn(x) => x > 2)", expected: false},
+		{input: "any([])", expected: false},
+		{input: "all([])", expected: true},
+		{input: "let mut n = 0; each([1, 2, 3], fn(x) { n += x }); n", expected: 6},
+		{input: "len(map([-1, 2], type))", expected: 2},
+		{input: "let mut calls = 0; any([1, 2, 3], fn(x) { calls++; return x == 2 }); calls", expected: 2},
+		{input: `map([1, 2], fn(x) => x + "a")`, expected: "[1:24] type mismatch: INTEGER + STRING"},
+		{input: `sort([1, "a"])`, expected: "[1:5] type mismatch: STRING < INTEGER"},
+		{input: "reduce([], fn(acc, x) => acc + x)", expected: "[1:7] reduce of an empty iterable with no initial value"},
+		{input: "map([1], fn(a, b) => a)", expected: "[1:4] wrong number of arguments for anonymous function: want 2, got 1"},
+		{input: "map([1], 2)", expected: "[1:4] argument to `map` must be FUNCTION, got INTEGER"},
+		{input: "filter(1, fn(x) => x)", expected: "[1:7] argument to `filter` must be iterable, got INTEGER"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestEvalErrorTraceback(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			input:    `let x = 1 + "a"`,
+			expected: "test.julu:1:11-12: type mismatch: INTEGER + STRING\n",
+		},
+		{
+			input: `fn f(x) {
+    return x + 1
+}
+fn g(x) => 2 * f(x)
+g("a")`,
+			expected: `traceback (most recent call last):
+  g called at test.julu:5:2
+  f called at test.julu:4:17
+test.julu:2:14-15: type mismatch: STRING + INTEGER
+`,
+		},
+		{
+			input: `fn f(n) {
+    if n == 0 {
+        return n + "a"
+    }
+    return 1 + f(n - 1)
+}
+map([1], fn(x) => 1 + f(x + 1))`,
+			expected: `traceback (most recent call last):
+  anonymous function called at test.julu:7:4
+  f called at test.julu:7:24
+  f called at test.julu:5:17 (2 times)
+test.julu:3:18-19: type mismatch: INTEGER + STRING
+`,
+		},
+		{
+			input:    "fn f(n) { if n == 0 { return undefined } return f(n - 1) }; f(3)",
+			expected: "traceback (most recent call last):\n  f called at test.julu:1:50\ntest.julu:1:30-39: identifier not found: undefined\n",
+		},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(bufio.NewReader(strings.NewReader(tt.input)))
+		p := parser.New(l)
+		program := p.Parse()
+		env := object.NewFileEnvironment("test.julu")
+		evaluated := evaluator.Eval(program, env)
+
+		err, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+			continue
+		}
+		if err.Traceback() != tt.expected {
+			t.Errorf("wrong traceback. got=%q, want=%q", err.Traceback(), tt.expected)
+		}
+	}
+}
+
+func TestEvalResultAndOption(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{input: "fn f(r) { let v = r?; return ok(v + 1) }; f(ok(1)) == ok(2)", expected: true},
+		{input: `fn f(r) { let v = r?; return ok(v + 1) }; f(err("e")) == err("e")`, expected: true},
+		{input: "fn f(o) => some(o? * 2); f(some(3)) == some(6)", expected: true},
+		{input: "fn f(o) => some(o? * 2); f(none) == none", expected: true},
+		{input: "fn f(xs) { for x in xs { x? } return 0 }; f([ok(1), err(2), ok(3)]) == err(2)", expected: true},
+		{input: "fn f(o) -> option { return some(o? + 1) }; f(none) == none", expected: true},
+		{input: `fn f(s) { return ok(parse_int(s)? + parse_int("1")?) }; f("41") == ok(42)`, expected: true},
+		{input: `parse_int("0x10") == ok(16)`, expected: true},
+		{input: `match parse_int("x") { case ok(n) => n case err(e) => e == "invalid integer: \"x\"" }`, expected: true},
+		{input: `match parse_float("2.5") { case ok(f) => f > 2 case err(_) => false }`, expected: true},
+		{input: `match read_file("/nonexistent/julu") { case ok(_) => 1 case err(_) => 2 }`, expected: 2},
+		{input: "match some(1) { case none => 1 case some(n) => n + 1 }", expected: 2},
+		{input: "match none { case some(n) => n case none => 0 }", expected: 0},
+		{input: "match ok([1, 2]) { case ok([a, b]) if a < b => b case _ => 0 }", expected: 2},
+		{input: "match err(1) { case ok(_) => 1 case err(2) => 2 case err(n) => n * 3 }", expected: 3},
+		{input: "ok(1) == ok(1.0)", expected: true},
+		{input: "ok(1) != err(1)", expected: true},
+		{input: "some(1) == none", expected: false},
+		{input: "ok(1) == some(1)", expected: "[1:7] type mismatch: RESULT == OPTION"},
+		{input: "fn f(x) => x?; f(1)", expected: "[1:13] cannot use ? on INTEGER, want RESULT or OPTION"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestEvalDeferStatement(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{input: `let mut log = ""; fn f() { defer fn() { log = log + "a" }(); defer fn() { log = log + "b" }(); log = log + "c" }; f(); log == "cba"`, expected: true},
+		{input: `let mut log = ""; fn add(s) { log = log + s }; fn f() { defer add("a"); return 1 }; f() + len(log)`, expected: 2},
+		{input: `let mut log = ""; fn add(s) { log = log + s }; fn f(x) { defer add(x); let x = "b"; add(x) }; f("a"); log == "ba"`, expected: true},
+		{input: `let mut log = ""; fn add(s) { log = log + s }; fn f(n) { defer add(f"{n}"); if n == 0 { return 0 } f(n - 1) }; f(3); log == "0123"`, expected: true},
+		{input: `let mut log = ""; fn add(s) { log = log + s }; for i in range(0, 3) { defer add(f"{i}"); if i == 1 { break } }; log == "01"`, expected: true},
+		{input: `let mut log = ""; fn add(s) { log = log + s }; fn f() { if true { defer add("a") } add("b") }; f(); log == "ab"`, expected: true},
+		{input: `let mut seen = 0; fn f() { defer fn(r) { seen = r }; return 42 }; f() + seen`, expected: 84},
+		{input: `let mut log = ""; fn add(s) { log = log + s }; fn f() { defer add("a"); let v = err(1)?; add("b") }; f() == err(1) && log == "a"`, expected: true},
+		{input: `let mut log = ""; fn add(s) { log = log + s }; fn f() { defer add("a"); 1 + "a" }; f()`, expected: "[1:75] type mismatch: INTEGER + STRING"},
+		{input: `fn f() { defer 1 + "a"; return 1 }; f()`, expected: "[1:18] type mismatch: INTEGER + STRING"},
+		{input: `fn f() { defer fn() { 1 + "a" }(); return 1 }; f()`, expected: "[1:25] type mismatch: INTEGER + STRING"},
+		{input: `fn f() { defer 3; return 1 }; f()`, expected: "[1:10] cannot defer INTEGER, want a call or a function"},
+		{input: `fn count(n, acc) { defer fn() {}; if n == 0 { return acc } count(n - 1, acc + 1) }; count(100, 0)`, expected: 100},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestEvalWithStatement(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "with.txt")
+	prelude := `let path = "` + path + `"; let mut keep = null` + "\n"
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{input: `with open(path, "w")? as fp => write(fp, "foobar")?`, expected: 6},
+		{input: `with open(path)? as fp { keep = fp; read(fp)? } == "foobar" && close(keep) != ok(null)`, expected: true},
+		{input: `fn f() { with open(path)? as fp { keep = fp; return 1 } }; f() + 1`, expected: 2},
+		{input: `fn f() { with open(path)? as fp { keep = fp; return 1 } }; f(); close(keep) == ok(null)`, expected: false},
+		{input: `fn f() { with open(path)? as fp { keep = fp; err("e")? } }; f() == err("e") && close(keep) != ok(null)`, expected: true},
+		{input: `fn f() { with open(path)? as fp { keep = fp; close(fp); 1 } }; f()`, expected: 1},
+		{input: `with open(path)? as fp { keep = fp; 1 + "a" }`, expected: "[2:39] type mismatch: INTEGER + STRING"},
+		{input: `with open(path)? as fp => match write(fp, "x") { case ok(_) => 1 case err(_) => 2 }`, expected: 2},
+		{input: `match open(path + ".missing") { case ok(_) => 1 case err(_) => 2 }`, expected: 2},
+		{input: `with 3 as x => x`, expected: "[2:1] cannot use INTEGER in a with statement, want a resource"},
+		{input: `let f = open(path)?; close(f); with f as fp => 1`, expected: "[2:32] file \"" + path + "\" is closed"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(prelude + tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestEvalStructs(t *testing.T) {
+	prelude := "struct Point { x: int, y: int }; struct Line { a: Point, b: Point }\n"
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{input: "let p = Point{x: 1, y: 2}; p.x + p.y", expected: 3},
+		{input: "let p = Point{y: 2, x: 1}; p.x", expected: 1},
+		{input: "let mut p = Point{x: 1, y: 2}; p.x = 5; p.x += 1; p.x", expected: 6},
+		{input: "let l = Line{a: Point{x: 1, y: 2}, b: Point{x: 3, y: 4}}; l.a.x + l.b.y", expected: 5},
+		{input: "let mut l = Line{a: Point{x: 1, y: 2}, b: Point{x: 3, y: 4}}; l.a.x = 10; l.a.x", expected: 10},
+		{input: "Point{x: 1, y: 2} == Point{x: 1, y: 2}", expected: true},
+		{input: "Point{x: 1, y: 2} != Point{x: 1, y: 3}", expected: true},
+		{input: "Line{a: Point{x: 1, y: 2}, b: Point{x: 3, y: 4}} == Line{a: Point{x: 1, y: 2}, b: Point{x: 3, y: 4}}", expected: true},
+		{input: "Line{a: Point{x: 1, y: 2}, b: Point{x: 3, y: 4}} == Line{a: Point{x: 1, y: 2}, b: Point{x: 3, y: 5}}", expected: false},
+		{input: "ok(Point{x: 1, y: 2}) == ok(Point{x: 1, y: 2})", expected: true},
+		{input: `type(Point{x: 1, y: 2}) == "Point"`, expected: true},
+		{input: "fn norm(p: Point) -> int => p.x * p.x + p.y * p.y; norm(Point{x: 3, y: 4})", expected: 25},
+		{input: "let p = Point{x: 1, y: 2}; if p == (Point{x: 1, y: 2}) { 1 } else { 2 }", expected: 1},
+		{input: "struct Pair { a: uint8, b: float }; let p = Pair{a: 300, b: 1}; p.a as int", expected: 44},
+		{input: "Point{x: 1}", expected: "[2:1] missing field y in Point literal"},
+		{input: "Point{x: 1, y: 2, z: 3}", expected: "[2:19] unknown field z in Point literal"},
+		{input: `Point{x: 1, y: "2"}`, expected: "[2:13] field y of Point: cannot use STRING as int"},
+		{input: "Point{x: 1, y: 2}.z", expected: "[2:18] Point has no field z"},
+		{input: "let mut p = Point{x: 1, y: 2}; p.x = true", expected: "[2:36] field x of Point: cannot use BOOLEAN as int"},
+		{input: "let p = 1; p.x", expected: "[2:13] cannot access field x of INTEGER"},
+		{input: "Nope{x: 1}", expected: "[2:1] identifier not found: Nope"},
+		{input: "Point{x: 1, y: 2} == Line{a: Point{x: 1, y: 2}, b: Point{x: 3, y: 4}}", expected: "[2:19] type mismatch: Point == Line"},
+		{input: "Point{x: 1, y: 2} < Point{x: 1, y: 2}", expected: "[2:19] unknown operator: Point < Point"},
+		{input: "fn f(l: Line) => l; f(Point{x: 1, y: 2})", expected: "[2:22] argument l of f: cannot use Point as Line"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(prelude + tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestEvalUnions(t *testing.T) {
+	prelude := "union Shape { Circle(r: float), Rect(w: float, h: float), Empty }\n"
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{input: "match Rect(2, 3) { case Circle(r) => r case Rect(w, h) => w * h case Empty => 0 } == 6.0", expected: true},
+		{input: "match Circle(r: 2) { case Circle(r) => r case Rect(w, h) => w * h case Empty => 0 } == 2.0", expected: true},
+		{input: "match Empty { case Circle(_) | Rect(_, _) => 1 case Empty => 2 }", expected: 2},
+		{input: "match Shape.Rect(1, 1) { case Rect(w, h) if w == h => 1 case _ => 2 }", expected: 1},
+		{input: "match Rect(1, 2) { case Empty => 1 } else => 2", expected: 2},
+		{input: "fn area(s: Shape) -> float => match s { case Circle(r) => 3 * r * r case Rect(w, h) => w * h case Empty => 0 }; area(Circle(1)) == 3.0", expected: true},
+		{input: "Rect(1, 2).h == 2.0", expected: true},
+		{input: `type(Circle(1)) == "Shape"`, expected: true},
+		{input: "Circle(1) == Circle(1.0)", expected: true},
+		{input: "Circle(1) != Rect(1, 1)", expected: true},
+		{input: "Empty == Empty", expected: true},
+		{input: "struct Layer { shape: Shape }; Layer{shape: Empty}.shape == Empty", expected: true},
+		{input: "match Circle(1) { case Square(s) => 1 case _ => 2 }", expected: "[2:24] Square is not a variant of Shape"},
+		{input: "match Circle(1) { case Circle(r) => 1 case Rect(w, h) if w > h => 2 }", expected: "[2:1] match on Shape does not handle Rect, Empty"},
+		{input: `Circle("a")`, expected: "[2:7] field r of Circle: cannot use STRING as float"},
+		{input: "Rect(1)", expected: "[2:5] wrong number of arguments for Rect: want=2, got=1"},
+		{input: "Circle(1).w", expected: "[2:10] Circle has no field w"},
+		{input: "Shape.Square", expected: "[2:6] Square is not a variant of Shape"},
+		{input: "fn f(s: Shape) => s; f(1)", expected: "[2:23] argument s of f: cannot use INTEGER as Shape"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(prelude + tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestEvalMethods(t *testing.T) {
+	prelude := `struct Counter { n: int, log: string }
+Counter => {
+    fn incr(self, by: int = 1) -> int { self.n += by; return self.n }
+    fn count(self, k: int) -> int { if k == 0 { return self.n } self.n += 1; self.count(k - 1) }
+    fn enter(self) { self.log += "<"; self }
+    fn exit(self) { self.log += ">" }
+}
+union Shape { Circle(r: float), Square(s: float) }
+Shape => {
+    fn area(self) -> float => match self { case Circle(r) => 3 * r * r case Square(s) => s * s }
+}
+let c = Counter{n: 0, log: ""}
+`
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{input: "c.incr(); c.incr(by: 5)", expected: 6},
+		{input: "c.incr(); c.n", expected: 1},
+		{input: "c.n = 3", expected: "[13:1] cannot assign to immutable variable: c"},
+		{input: "Counter.incr(c, 2)", expected: 2},
+		{input: "c.count(50000)", expected: 50000},
+		{input: "reduce(map([1, 2], c.incr), fn(a, b) => a + b)", expected: 4},
+		{input: "Square(3).area() == 9.0", expected: true},
+		{input: `type(c.incr) == "Counter.incr"`, expected: true},
+		{input: `with c as cc { cc.incr() }; c.log == "<>"`, expected: true},
+		{input: `fn f() { with c as cc { cc.incr(); return 1 } }; f(); c.log == "<>" && c.n == 1`, expected: true},
+		{input: `fn f() { with c as cc { defer cc.incr(); let v = err(1)?; 1 } }; f() == err(1) && c.log == "<>" && c.n == 1`, expected: true},
+		{input: `Counter => { fn n(self) => 42 }; c.n()`, expected: 42},
+		{input: `Counter => { fn incr(self) => 0 }`, expected: "[13:17] method incr of Counter is already defined"},
+		{input: `c.nope()`, expected: "[13:2] Counter has no field nope"},
+		{input: `let x = 5; x.area()`, expected: "[13:13] cannot access field area of INTEGER"},
+		{input: `let x = 5; x => { fn f(self) => 1 }`, expected: "[13:12] cannot bind methods to INTEGER"},
+		{input: `Nope => { fn f(self) => 1 }`, expected: "[13:1] identifier not found: Nope"},
+		{input: `c.incr("a")`, expected: "[13:7] argument by of Counter.incr: cannot use STRING as int"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(prelude + tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestEvalNamedTypes(t *testing.T) {
+	prelude := `type Port = uint16
+type UserID int
+type OrderID int
+UserID => {
+    fn next(self) -> UserID => self + (1 as UserID)
+}
+let u = 42 as UserID
+let o = 7 as OrderID
+fn order(user: UserID, id: OrderID) -> int => (user as int) * 100 + (id as int)
+`
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{input: `let p: Port = 8080; type(p) == "UINT16"`, expected: true},
+		{input: "let p = 70000 as Port; p == 4464", expected: true},
+		{input: `type(u) == "UserID"`, expected: true},
+		{input: "u as int", expected: 42},
+		{input: "let w = u + (1 as UserID); w as int", expected: 43},
+		{input: `type(-u) == "UserID"`, expected: true},
+		{input: "u < (50 as UserID)", expected: true},
+		{input: "u == 42:UserID", expected: true},
+		{input: "order(u, o)", expected: 4207},
+		{input: `u.next() == (43 as UserID) && type(UserID.next(u)) == "UserID"`, expected: true},
+		{input: "let mut v = u; v += 1 as UserID; v as int", expected: 43},
+		{input: "match u { case 42 => 1 case _ => 2 }", expected: 2},
+		{input: "type Flag bool; let f = true as Flag; if f { 1 } else { 2 }", expected: 1},
+		{input: `type Name string; let n = "bob" as Name; type(n) == "Name" && (n as string) == "bob"`, expected: true},
+		{input: `type Name string; ("a" as Name) + ("b" as Name) == ("ab" as Name)`, expected: true},
+		{input: `type Name string; 1 as Name`, expected: "[10:21] cannot convert INTEGER to string"},
+		{input: "type B = int; type A = B; type B = A; let x: A = 1; x", expected: 1},
+		{input: "type B = int; type A = B; let B = A; let x: A = 2; x", expected: 2},
+		{input: "type M int; type N M; let M = N; (3 as N) as int", expected: 3},
+		{input: "order(o, u)", expected: "[10:6] argument user of order: cannot use OrderID as UserID"},
+		{input: "u + 1", expected: "[10:3] type mismatch: UserID + INTEGER"},
+		{input: "u == o", expected: "[10:3] type mismatch: UserID == OrderID"},
+		{input: "let x: UserID = 5", expected: "[10:1] cannot use INTEGER as UserID"},
+		{input: "let mut v = u; v = 5", expected: "[10:18] cannot assign INTEGER to variable of type UserID"},
+		{input: "type Bad = Nope", expected: "[10:12] unknown type: Nope"},
+		{input: "Port => { fn f(self) => 1 }", expected: "[10:1] cannot bind methods to TYPE"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(prelude + tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestEvalMatchExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{input: "match 2 { case 1 => 10 case 2 => 20 }", expected: 20},
+		{input: "match 2 { case true => 10 case _ => 20 }", expected: 20},
+		{input: "match 3 { case 1 => 10 } else => 30", expected: 30},
+		{input: "match 2 { case 1 | 2 => 10 case _ => 20 }", expected: 10},
+		{input: "match 2.0 { case 2 => 10 case _ => 20 }", expected: 10},
+		{input: "match 7 { case 0..5 => 1 case 5..=7 => 2 case _ => 3 }", expected: 2},
+		{input: "match 5 { case 0..5 => 1 case _ => 3 }", expected: 3},
+		{input: "match -3 { case -5..0 => 1 case _ => 3 }", expected: 1},
+		{input: "match 'q' { case 'a'..='z' => 1 case _ => 3 }", expected: 1},
+		{input: "match 4 { case n if n > 3 => n * 2 case n => n }", expected: 8},
+		{input: "match 2 { case n if n > 3 => n * 2 case n => n }", expected: 2},
+		{input: "match [1, 2, 3] { case [a, b] => a case [a, _, c] => a + c }", expected: 4},
+		{input: "match [1, 2, 3] { case [a, ...rest] => len(rest) }", expected: 2},
+		{input: "match [] { case [a, ...rest] => 1 case [] => 2 }", expected: 2},
+		{input: `match {"k": 1, "v": 2} { case {"k": 2} => 1 case {"k": 1, "v": v} => v }`, expected: 2},
+		{input: `match {"k": [1, 2]} { case {"k": [_, x]} if x == 2 => x case _ => 0 }`, expected: 2},
+		{input: "match 1 { case [a] | a => a }", expected: 1},
+		{input: "match 1 { case n if m => n }", expected: "[1:21] identifier not found: m"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
+func testEval(input string) object.Object {
+	l := lexer.New(bufio.NewReader(strings.NewReader(input)))
+	p := parser.New(l)
+	program := p.Parse()
+	env := object.NewEnvironment()
+	return evaluator.Eval(program, env)
+}
+
+func testErrorObject(t *testing.T, obj object.Object, expected string) {
+	result, ok := obj.(*object.Error)
+	if !ok {
+		t.Errorf("object is not Error. got=%T (%+v)", obj, obj)
+		return
+	}
+
+	if result.Error() != expected {
+		t.Errorf("error has wrong message. got=%q, want=%q", result.Error(), expected)
+	}
+}
+
 func testIntegerObject(t *testing.T, obj object.Object, expected int64) {
 	result, ok := obj.(*object.Integer)
 	if !ok {
@@ -143,6 +1116,35 @@ func testIntegerObject(t *testing.T, obj object.Object, expected int64) {
 	}
 }
 
+type sizedInteger struct {
+	typ   object.ObjectType
+	value string
+}
+
+func testSizedIntegerObject(t *testing.T, obj object.Object, expected sizedInteger) {
+	result, ok := obj.(*object.SizedInteger)
+	if !ok {
+		t.Errorf("object is not SizedInteger. got=%T (%+v)", obj, obj)
+		return
+	}
+
+	if result.Type() != expected.typ || result.Inspect() != expected.value {
+		t.Errorf("object has wrong value. got=%s(%s), want=%s(%s)", result.Type(), result.Inspect(), expected.typ, expected.value)
+	}
+}
+
+func testFloatObject(t *testing.T, obj object.Object, expected float64) {
+	result, ok := obj.(*object.Float)
+	if !ok {
+		t.Errorf("object is not Float. got=%T (%+v)", obj, obj)
+		return
+	}
+
+	if result.Value != expected {
+		t.Errorf("object has wrong value. got=%f, want=%f", result.Value, expected)
+	}
+}
+
 func testBooleanObject(t *testing.T, obj object.Object, expected bool) {
 	result, ok := obj.(*object.Boolean)
 	if !ok {
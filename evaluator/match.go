@@ -0,0 +1,154 @@
+package evaluator
+
+import (
+	"github.com/poolpOrg/julu/ast"
+	"github.com/poolpOrg/julu/object"
+)
+
+// matchPattern reports whether val matches pattern, recording the values
+// bound by the pattern in bindings. Patterns never fail with an error, a
+// value of an unexpected type simply doesn't match.
+func matchPattern(pattern ast.Pattern, val object.Object, env *object.Environment, bindings map[string]object.Object) bool {
+	switch pattern := pattern.(type) {
+	case *ast.WildcardPattern:
+		return true
+
+	case *ast.BindingPattern:
+		if variant, ok := unitVariant(pattern.Name.Value, val); ok {
+			return val.(*object.Union).Variant == variant
+		}
+		bindings[pattern.Name.Value] = val
+		return true
+
+	case *ast.LiteralPattern:
+		literal := Eval(pattern.Value, env)
+		if isError(literal) {
+			return false
+		}
+		return valuesEqual(val, literal)
+
+	case *ast.RangePattern:
+		return matchRange(pattern, val, env)
+
+	case *ast.ArrayPattern:
+		array, ok := val.(*object.Array)
+		if !ok {
+			return false
+		}
+		if len(array.Elements) < len(pattern.Elements) ||
+			(pattern.Rest == nil && len(array.Elements) != len(pattern.Elements)) {
+			return false
+		}
+		for i, element := range pattern.Elements {
+			if !matchPattern(element, array.Elements[i], env, bindings) {
+				return false
+			}
+		}
+		if pattern.Rest != nil {
+			rest := make([]object.Object, len(array.Elements)-len(pattern.Elements))
+			copy(rest, array.Elements[len(pattern.Elements):])
+			return matchPattern(pattern.Rest, &object.Array{Elements: rest}, env, bindings)
+		}
+		return true
+
+	case *ast.HashPattern:
+		hash, ok := val.(*object.Hash)
+		if !ok {
+			return false
+		}
+		for i, keyNode := range pattern.Keys {
+			key, ok := Eval(keyNode, env).(object.Hashable)
+			if !ok {
+				return false
+			}
+			pair, ok := hash.Pairs[key.HashKey()]
+			if !ok || !matchPattern(pattern.Values[i], pair.Value, env, bindings) {
+				return false
+			}
+		}
+		return true
+
+	case *ast.VariantPattern:
+		arguments, ok := variantArguments(pattern.Name.Value, val)
+		if !ok || len(arguments) != len(pattern.Arguments) {
+			return false
+		}
+		for i, argument := range pattern.Arguments {
+			if !matchPattern(argument, arguments[i], env, bindings) {
+				return false
+			}
+		}
+		return true
+
+	case *ast.AlternativePattern:
+		for _, alternative := range pattern.Alternatives {
+			// only the bindings of the alternative that matched are kept
+			candidate := make(map[string]object.Object)
+			if matchPattern(alternative, val, env, candidate) {
+				for name, value := range candidate {
+					bindings[name] = value
+				}
+				return true
+			}
+		}
+		return false
+	}
+
+	return false
+}
+
+// variantArguments returns the arguments val was built with, if it was
+// built by the variant constructor name.
+func variantArguments(name string, val object.Object) ([]object.Object, bool) {
+	switch val := val.(type) {
+	case *object.Result:
+		if (val.Ok && name == "ok") || (!val.Ok && name == "err") {
+			return []object.Object{val.Value}, true
+		}
+	case *object.Option:
+		if val.Value != nil && name == "some" {
+			return []object.Object{val.Value}, true
+		}
+	case *object.Union:
+		if val.Variant.Name.Value == name {
+			return val.Values, true
+		}
+	}
+	return nil, false
+}
+
+func matchRange(pattern *ast.RangePattern, val object.Object, env *object.Environment) bool {
+	low := Eval(pattern.Low, env)
+	high := Eval(pattern.High, env)
+
+	upper := "<"
+	if pattern.Inclusive {
+		upper = "<="
+	}
+	return evalInfixExpression(">=", val, low) == TRUE &&
+		evalInfixExpression(upper, val, high) == TRUE
+}
+
+// valuesEqual compares a value to a literal, numbers of different types are
+// compared after the usual promotions so that case 1 matches 1.0 or 1:uint8.
+func valuesEqual(val, literal object.Object) bool {
+	isNumber := func(obj object.Object) bool {
+		return isInteger(obj) || obj.Type() == object.FLOAT_OBJ
+	}
+	if isNumber(val) && isNumber(literal) {
+		return evalInfixExpression("==", val, literal) == TRUE
+	}
+	if left, ok := val.(*object.Struct); ok {
+		right, ok := literal.(*object.Struct)
+		return ok && structsEqual(left, right)
+	}
+	if left, ok := val.(*object.Union); ok {
+		right, ok := literal.(*object.Union)
+		return ok && unionsEqual(left, right)
+	}
+	if left, ok := val.(*object.Named); ok {
+		right, ok := literal.(*object.Named)
+		return ok && namedEqual(left, right)
+	}
+	return isEqual(val, literal)
+}
@@ -0,0 +1,98 @@
+package evaluator
+
+import (
+	"github.com/poolpOrg/julu/ast"
+	"github.com/poolpOrg/julu/object"
+)
+
+// evalMethodBlockStatement binds the methods of the block to the type they
+// are declared for, a method can only be declared once per type.
+func evalMethodBlockStatement(node *ast.MethodBlockStatement, env *object.Environment) object.Object {
+	definition, ok := env.Get(node.Type.Value)
+	if !ok {
+		return newError("identifier not found: %s", node.Type.Value)
+	}
+	owner, methods := methodTable(definition)
+	if methods == nil {
+		return newError("cannot bind methods to %s", definition.Type())
+	}
+
+	for _, method := range node.Methods {
+		if _, ok := methods[method.Name.Value]; ok {
+			return newErrorAt(method.Name.Token, "method %s of %s is already defined", method.Name.Value, owner)
+		}
+		methods[method.Name.Value] = &object.Function{
+			Name:        method.Name,
+			Owner:       owner,
+			Parameters:  method.Parameters,
+			ReturnTypes: method.ReturnTypes,
+			Body:        method.Body,
+			Env:         env,
+		}
+	}
+	return nil
+}
+
+// methodTable returns the name of the type definition and the methods bound
+// to it, creating the table on first use. The table is nil if methods can't
+// be bound to definition.
+func methodTable(definition object.Object) (string, map[string]*object.Function) {
+	switch definition := definition.(type) {
+	case *object.StructType:
+		if definition.Methods == nil {
+			definition.Methods = make(map[string]*object.Function)
+		}
+		return definition.Name, definition.Methods
+	case *object.UnionType:
+		if definition.Methods == nil {
+			definition.Methods = make(map[string]*object.Function)
+		}
+		return definition.Name, definition.Methods
+	case *object.NamedType:
+		if definition.Methods == nil {
+			definition.Methods = make(map[string]*object.Function)
+		}
+		return definition.Name, definition.Methods
+	case *object.TypeAlias:
+		target, _ := definition.Env.Get(definition.Target)
+		return methodTable(target)
+	}
+	return "", nil
+}
+
+// typeMethods returns the methods bound to a type definition, or to the type
+// of a value.
+func typeMethods(obj object.Object) map[string]*object.Function {
+	switch obj := obj.(type) {
+	case *object.StructType:
+		return obj.Methods
+	case *object.UnionType:
+		return obj.Methods
+	case *object.NamedType:
+		return obj.Methods
+	case *object.TypeAlias:
+		target, _ := obj.Env.Get(obj.Target)
+		return typeMethods(target)
+	case *object.Struct:
+		return obj.Definition.Methods
+	case *object.Union:
+		return obj.Definition.Methods
+	case *object.Named:
+		return obj.Definition.Methods
+	}
+	return nil
+}
+
+// lookupMethod returns the method name of the type of val bound to val, if
+// there is one.
+func lookupMethod(val object.Object, name string) (*object.BoundMethod, bool) {
+	switch val.(type) {
+	case *object.StructType, *object.UnionType, *object.NamedType, *object.TypeAlias:
+		return nil, false
+	}
+	method, ok := typeMethods(val)[name]
+	if !ok {
+		return nil, false
+	}
+	return &object.BoundMethod{Receiver: val, Method: method}, true
+}
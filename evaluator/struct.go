@@ -0,0 +1,137 @@
+package evaluator
+
+import (
+	"github.com/poolpOrg/julu/ast"
+	"github.com/poolpOrg/julu/object"
+)
+
+func evalStructStatement(node *ast.StructStatement, env *object.Environment) object.Object {
+	definition := &object.StructType{Name: node.Name.Value, Fields: node.Fields, Env: env}
+	env.Set(node.Name.Value, definition)
+	return nil
+}
+
+// evalStructLiteral builds a struct, every field of its type must be given
+// a value of the declared type.
+func evalStructLiteral(node *ast.StructLiteral, env *object.Environment) object.Object {
+	obj, ok := env.Get(node.Name.Value)
+	if !ok {
+		return newError("identifier not found: %s", node.Name.Value)
+	}
+	definition, ok := obj.(*object.StructType)
+	if !ok {
+		return newError("%s is not a struct", node.Name.Value)
+	}
+
+	fields := make(map[string]object.Object, len(definition.Fields))
+	for _, field := range node.Fields {
+		decl := definition.Field(field.Name.Value)
+		if decl == nil {
+			return newErrorAt(field.Name.Token, "unknown field %s in %s literal", field.Name.Value, definition.Name)
+		}
+		val := Eval(field.Value, env)
+		if isError(val) {
+			return val
+		}
+		val = evalTypedValue(val, decl.Type.Value, definition.Env)
+		if err, ok := val.(*object.Error); ok {
+			return newErrorAt(field.Name.Token, "field %s of %s: %s", decl.Name.Value, definition.Name, err.Message)
+		}
+		fields[decl.Name.Value] = val
+	}
+
+	for _, decl := range definition.Fields {
+		if _, ok := fields[decl.Name.Value]; !ok {
+			return newError("missing field %s in %s literal", decl.Name.Value, definition.Name)
+		}
+	}
+	return &object.Struct{Definition: definition, Fields: fields}
+}
+
+// evalFieldExpression reads a field of a struct or of the active variant of
+// a union value, or a variant of a union type, Shape.Circle. Methods take
+// priority: on a value they are bound to it, on a type they are returned as
+// is and take the value as first argument.
+func evalFieldExpression(left object.Object, name string) object.Object {
+	if method, ok := lookupMethod(left, name); ok {
+		return method
+	}
+	if method, ok := typeMethods(left)[name]; ok {
+		return method
+	}
+
+	switch left := left.(type) {
+	case *object.Union:
+		return evalUnionField(left, name)
+	case *object.UnionType:
+		variant := left.Variant(name)
+		if variant == nil {
+			return newError("%s is not a variant of %s", name, left.Name)
+		}
+		return variantConstructor(left, variant)
+	}
+
+	s, ok := left.(*object.Struct)
+	if !ok {
+		return newError("cannot access field %s of %s", name, left.Type())
+	}
+	val, ok := s.Fields[name]
+	if !ok {
+		return newError("%s has no field %s", s.Definition.Name, name)
+	}
+	return val
+}
+
+// evalFieldAssignment assigns a field of a struct, the new value must have
+// the declared type of the field.
+func evalFieldAssignment(operator string, left object.Object, name string, value object.Object) object.Object {
+	s, ok := left.(*object.Struct)
+	if !ok {
+		return newError("cannot assign field %s of %s", name, left.Type())
+	}
+	decl := s.Definition.Field(name)
+	if decl == nil {
+		return newError("%s has no field %s", s.Definition.Name, name)
+	}
+
+	current := s.Fields[name]
+	newValue := evalOperatorAssignment(operator, current, value)
+	if isError(newValue) {
+		return newValue
+	}
+	newValue = evalTypedValue(newValue, decl.Type.Value, s.Definition.Env)
+	if err, ok := newValue.(*object.Error); ok {
+		return newError("field %s of %s: %s", name, s.Definition.Name, err.Message)
+	}
+	s.Fields[name] = newValue
+	return assignResult(operator, current, newValue)
+}
+
+func evalStructInfixExpression(operator string, left, right *object.Struct) object.Object {
+	switch operator {
+	case "==", "is":
+		return nativeBoolToBooleanObject(structsEqual(left, right))
+	case "!=":
+		return nativeBoolToBooleanObject(!structsEqual(left, right))
+	}
+	return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+}
+
+// structsEqual reports whether two structs have the same type and equal
+// fields, nested structs are compared field by field.
+func structsEqual(left, right *object.Struct) bool {
+	if left.Definition != right.Definition {
+		return false
+	}
+	for name, val := range left.Fields {
+		if !valuesEqual(val, right.Fields[name]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isStruct(obj object.Object) bool {
+	_, ok := obj.(*object.Struct)
+	return ok
+}
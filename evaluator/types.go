@@ -0,0 +1,110 @@
+package evaluator
+
+import (
+	"strings"
+
+	"github.com/poolpOrg/julu/ast"
+	"github.com/poolpOrg/julu/object"
+)
+
+// evalTypeStatement binds an alias or a named type, the type it refers to
+// must already be known.
+func evalTypeStatement(node *ast.TypeStatement, env *object.Environment) object.Object {
+	if !isKnownType(node.Type.Value, env) {
+		return newErrorAt(node.Type.Token, "unknown type: %s", node.Type.Value)
+	}
+
+	// the type is resolved in an environment holding the definition its name
+	// refers to now, rebinding that name can't make types refer to each
+	// other in a cycle
+	typeEnv := object.NewEnclosedEnvironment(env)
+	name := strings.Trim(node.Type.Value, "[]")
+	if definition, ok := env.Get(name); ok {
+		typeEnv.Set(name, definition)
+	}
+
+	if node.Alias {
+		env.Set(node.Name.Value, &object.TypeAlias{Name: node.Name.Value, Target: node.Type.Value, Env: typeEnv})
+	} else {
+		env.Set(node.Name.Value, &object.NamedType{Name: node.Name.Value, Underlying: node.Type.Value, Env: typeEnv})
+	}
+	return nil
+}
+
+// isKnownType reports whether typeName is a builtin type or a type declared
+// in env, arrays of known types included.
+func isKnownType(typeName string, env *object.Environment) bool {
+	if strings.HasPrefix(typeName, "[") && strings.HasSuffix(typeName, "]") {
+		return isKnownType(typeName[1:len(typeName)-1], env)
+	}
+	if _, ok := object.DeclaredTypes[typeName]; ok {
+		return true
+	}
+	definition, _ := env.Get(typeName)
+	switch definition.(type) {
+	case *object.StructType, *object.UnionType, *object.TypeAlias, *object.NamedType:
+		return true
+	}
+	return false
+}
+
+// evalNamedCast converts val to typeName, which may be declared in env. A
+// cast to a named type converts the value to its underlying type and wraps
+// it, a value of a named type is unwrapped before it is converted.
+func evalNamedCast(val object.Object, typeName string, env *object.Environment) object.Object {
+	for named, ok := val.(*object.Named); ok; named, ok = val.(*object.Named) {
+		val = named.Value
+	}
+
+	if _, ok := object.DeclaredTypes[typeName]; ok {
+		return evalCastExpression(val, typeName)
+	}
+
+	definition, _ := env.Get(typeName)
+	switch definition := definition.(type) {
+	case *object.TypeAlias:
+		return evalNamedCast(val, definition.Target, definition.Env)
+	case *object.NamedType:
+		converted := evalNamedCast(val, definition.Underlying, definition.Env)
+		if isError(converted) {
+			return converted
+		}
+		return &object.Named{Definition: definition, Value: converted}
+	case *object.StructType, *object.UnionType:
+		return evalTypedValue(val, typeName, env)
+	}
+	if strings.HasPrefix(typeName, "[") && strings.HasSuffix(typeName, "]") {
+		return evalTypedValue(val, typeName, env)
+	}
+	return evalCastExpression(val, typeName)
+}
+
+// evalNamedInfixExpression evaluates an operator on values of the same named
+// type, on their underlying values. A result of the underlying type is of the
+// named type, comparisons are plain booleans.
+func evalNamedInfixExpression(operator string, left, right object.Object) object.Object {
+	l, lok := left.(*object.Named)
+	r, rok := right.(*object.Named)
+	if !lok || !rok || l.Definition != r.Definition {
+		return newError("type mismatch: %s %s %s", left.Type(), operator, right.Type())
+	}
+	return wrapNamed(l.Definition, l.Value, evalInfixExpression(operator, l.Value, r.Value))
+}
+
+// wrapNamed returns result as a value of definition if it has the type of
+// the underlying value it was computed from.
+func wrapNamed(definition *object.NamedType, underlying, result object.Object) object.Object {
+	if isError(result) || result.Type() != underlying.Type() {
+		return result
+	}
+	return &object.Named{Definition: definition, Value: result}
+}
+
+func namedEqual(left, right *object.Named) bool {
+	return left.Definition == right.Definition && valuesEqual(left.Value, right.Value)
+}
+
+func isNamed(obj object.Object) bool {
+	_, ok := obj.(*object.Named)
+	return ok
+}
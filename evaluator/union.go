@@ -0,0 +1,157 @@
+package evaluator
+
+import (
+	"strings"
+
+	"github.com/poolpOrg/julu/ast"
+	"github.com/poolpOrg/julu/object"
+)
+
+// evalUnionStatement binds the union and the constructors of its variants. A
+// variant without fields is bound to its only value.
+func evalUnionStatement(node *ast.UnionStatement, env *object.Environment) object.Object {
+	definition := &object.UnionType{Name: node.Name.Value, Variants: node.Variants, Env: env}
+	env.Set(node.Name.Value, definition)
+	for _, variant := range node.Variants {
+		env.Set(variant.Name.Value, variantConstructor(definition, variant))
+	}
+	return nil
+}
+
+// variantConstructor returns the builtin building variant from the values of
+// its fields, given in order or by name, or the value of variant if it has
+// no fields.
+func variantConstructor(definition *object.UnionType, variant *ast.UnionVariant) object.Object {
+	if variant.Fields == nil {
+		return &object.Union{Definition: definition, Variant: variant}
+	}
+
+	names := make([]string, len(variant.Fields))
+	for i, field := range variant.Fields {
+		names[i] = field.Name.Value
+	}
+	return &object.Builtin{
+		Name:       variant.Name.Value,
+		Parameters: names,
+		Fn: func(ctx object.Context, args ...object.Object) object.Object {
+			if len(args) != len(variant.Fields) {
+				return newError("wrong number of arguments for %s: want=%d, got=%d",
+					variant.Name.Value, len(variant.Fields), len(args))
+			}
+			values := make([]object.Object, len(args))
+			for i, field := range variant.Fields {
+				values[i] = evalTypedValue(args[i], field.Type.Value, definition.Env)
+				if err, ok := values[i].(*object.Error); ok {
+					return newError("field %s of %s: %s", field.Name.Value, variant.Name.Value, err.Message)
+				}
+			}
+			return &object.Union{Definition: definition, Variant: variant, Values: values}
+		},
+	}
+}
+
+// evalUnionField reads a field of the active variant of u.
+func evalUnionField(u *object.Union, name string) object.Object {
+	for i, field := range u.Variant.Fields {
+		if field.Name.Value == name {
+			return u.Values[i]
+		}
+	}
+	return newError("%s has no field %s", u.Variant.Name.Value, name)
+}
+
+func evalUnionInfixExpression(operator string, left, right *object.Union) object.Object {
+	switch operator {
+	case "==", "is":
+		return nativeBoolToBooleanObject(unionsEqual(left, right))
+	case "!=":
+		return nativeBoolToBooleanObject(!unionsEqual(left, right))
+	}
+	return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+}
+
+// unionsEqual reports whether two union values are the same variant of the
+// same union with equal fields.
+func unionsEqual(left, right *object.Union) bool {
+	if left.Definition != right.Definition || left.Variant != right.Variant {
+		return false
+	}
+	for i, val := range left.Values {
+		if !valuesEqual(val, right.Values[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// unitVariant reports whether a name used as a pattern designates a variant
+// without fields of the union val belongs to, rather than a binding.
+func unitVariant(name string, val object.Object) (*ast.UnionVariant, bool) {
+	u, ok := val.(*object.Union)
+	if !ok {
+		return nil, false
+	}
+	variant := u.Definition.Variant(name)
+	return variant, variant != nil && variant.Fields == nil
+}
+
+// checkUnionMatch reports the arms of a match on a value of the union
+// definition naming variants it doesn't have, and the variants no arm
+// handles when the match has no catch-all arm or else block.
+func checkUnionMatch(node *ast.MatchExpression, definition *object.UnionType) object.Object {
+	handled := make(map[string]bool)
+	catchAll := node.Alternative != nil
+
+	var visit func(pattern ast.Pattern, guarded bool) *object.Error
+	visit = func(pattern ast.Pattern, guarded bool) *object.Error {
+		switch pattern := pattern.(type) {
+		case *ast.WildcardPattern:
+			catchAll = catchAll || !guarded
+		case *ast.BindingPattern:
+			if definition.Variant(pattern.Name.Value) == nil {
+				catchAll = catchAll || !guarded
+			} else if !guarded {
+				handled[pattern.Name.Value] = true
+			}
+		case *ast.VariantPattern:
+			if definition.Variant(pattern.Name.Value) == nil {
+				return newErrorAt(pattern.Token, "%s is not a variant of %s", pattern.Name.Value, definition.Name)
+			}
+			if !guarded {
+				handled[pattern.Name.Value] = true
+			}
+		case *ast.AlternativePattern:
+			for _, alternative := range pattern.Alternatives {
+				if err := visit(alternative, guarded); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	for _, arm := range node.MatchBlock.Cases {
+		if err := visit(arm.Pattern, arm.Guard != nil); err != nil {
+			return err
+		}
+	}
+	if catchAll {
+		return nil
+	}
+
+	missing := []string{}
+	for _, variant := range definition.Variants {
+		if !handled[variant.Name.Value] {
+			missing = append(missing, variant.Name.Value)
+		}
+	}
+	if len(missing) != 0 {
+		return newErrorAt(node.Token, "match on %s does not handle %s",
+			definition.Name, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+func isUnion(obj object.Object) bool {
+	_, ok := obj.(*object.Union)
+	return ok
+}
@@ -0,0 +1,68 @@
+package evaluator
+
+import (
+	"github.com/poolpOrg/julu/ast"
+	"github.com/poolpOrg/julu/object"
+)
+
+// evalWithStatement enters the resource, runs the body with the entered
+// value bound to the name and exits the resource once the body is done,
+// even if it failed or returned. The with statement evaluates to the value
+// of its body, an error raised when exiting replaces it unless the body
+// already failed.
+func evalWithStatement(node *ast.WithStatement, env *object.Environment) object.Object {
+	resource := Eval(node.Resource, env)
+	if isError(resource) {
+		return resource
+	}
+
+	value := locate(enterResource(resource, env), node.Token, env)
+	if isError(value) {
+		return value
+	}
+
+	withEnv := object.NewEnclosedEnvironment(env)
+	if node.Name.Value != "_" {
+		withEnv.Set(node.Name.Value, value)
+	}
+	result := completeTailCall(evalBlockStatement(node.Body, withEnv), env)
+
+	out := locate(exitResource(resource, env), node.Token, env)
+	if err, ok := out.(*object.Error); ok {
+		if _, failed := result.(*object.Error); !failed {
+			result = err
+		}
+	}
+	return result
+}
+
+// enterResource returns the value bound by a with block managing resource,
+// or an error if it cannot be managed. Besides builtin resources, a value
+// whose type has an exit method is a resource, its enter method returns
+// the value bound if there is one, the value itself is bound otherwise.
+func enterResource(resource object.Object, env *object.Environment) object.Object {
+	if resource, ok := resource.(object.Resource); ok {
+		return resource.Enter()
+	}
+	if _, ok := lookupMethod(resource, "exit"); ok {
+		if enter, ok := lookupMethod(resource, "enter"); ok {
+			return callFunction(env.Frame(), nil, enter, nil, nil)
+		}
+		return resource
+	}
+	return newError("cannot use %s in a with statement, want a resource", resource.Type())
+}
+
+// exitResource releases a resource entered by enterResource, only errors
+// are kept from the result of an exit method.
+func exitResource(resource object.Object, env *object.Environment) object.Object {
+	if resource, ok := resource.(object.Resource); ok {
+		return resource.Exit()
+	}
+	if exit, ok := lookupMethod(resource, "exit"); ok {
+		if err, ok := callFunction(env.Frame(), nil, exit, nil, nil).(*object.Error); ok {
+			return err
+		}
+	}
+	return nil
+}
@@ -17,6 +17,7 @@ const (
 	MUL  = "MUL"
 	DIV  = "DIV"
 	MOD  = "MOD"
+	POW  = "POW"
 	INCR = "INCR"
 	DECR = "DECR"
 
@@ -61,6 +62,7 @@ const (
 	IDENTIFIER = "IDENTIFIER"
 
 	ARROW                = "ARROW"
+	THIN_ARROW           = "THIN_ARROW"
 	LEFT_PARENTHESIS     = "LEFT_PARENTHESIS"
 	RIGHT_PARENTHESIS    = "RIGHT_PARENTHESIS"
 	LEFT_CURLY_BRACKET   = "LEFT_CURLY_BRACKET"
@@ -72,12 +74,18 @@ const (
 	SEMICOLON = "SEMICOLON"
 	COLON     = "COLON"
 	DOT       = "DOT"
+	QUESTION  = "QUESTION"
+
+	DOTDOT       = "DOTDOT"
+	DOTDOT_EQUAL = "DOTDOT_EQUAL"
+	ELLIPSIS     = "ELLIPSIS"
 
 	IS = "IS"
 	IN = "IN"
 
 	// Keywords
 	NULL  = "NULL"
+	NONE  = "NONE"
 	TRUE  = "TRUE"
 	FALSE = "FALSE"
 
@@ -87,11 +95,19 @@ const (
 	MATCH  = "MATCH"
 	CASE   = "CASE"
 	RETURN = "RETURN"
+	DEFER  = "DEFER"
+	WITH   = "WITH"
 
 	LET = "LET"
+	MUT = "MUT"
 
 	FN = "FN"
 
+	STRUCT = "STRUCT"
+	UNION  = "UNION"
+	SELF   = "SELF"
+	TYPE   = "TYPE"
+
 	FOR      = "FOR"
 	LOOP     = "LOOP"
 	WHILE    = "WHILE"
@@ -115,8 +131,10 @@ const (
 
 var keywords = map[string]TokenType{
 	"let": LET,
+	"mut": MUT,
 
 	"null": NULL,
+	"none": NONE,
 
 	"is":  IS,
 	"in":  IN,
@@ -129,8 +147,15 @@ var keywords = map[string]TokenType{
 	"if":     IF,
 	"else":   ELSE,
 	"return": RETURN,
+	"defer":  DEFER,
+	"with":   WITH,
 	"fn":     FN,
 
+	"struct": STRUCT,
+	"union":  UNION,
+	"self":   SELF,
+	"type":   TYPE,
+
 	"loop":     LOOP,
 	"while":    WHILE,
 	"until":    UNTIL,
@@ -280,6 +305,8 @@ func (l *Lexer) Lex() Token {
 			return tokenFromLexer(SEMICOLON, startPos, string(r))
 		case ':':
 			return tokenFromLexer(COLON, startPos, string(r))
+		case '?':
+			return tokenFromLexer(QUESTION, startPos, string(r))
 		case '.':
 			nextR, _, err := l.reader.ReadRune()
 			if err == nil {
@@ -288,6 +315,18 @@ func (l *Lexer) Lex() Token {
 					l.backup()
 					tokenType, lit := l.lexNumber(FLOAT)
 					return tokenFromLexer(tokenType, startPos, "."+lit)
+				} else if nextR == '.' {
+					nextR, _, err := l.reader.ReadRune()
+					if err == nil {
+						l.pos.column++
+						if nextR == '.' {
+							return tokenFromLexer(ELLIPSIS, startPos, "...")
+						} else if nextR == '=' {
+							return tokenFromLexer(DOTDOT_EQUAL, startPos, "..=")
+						}
+						l.backup()
+					}
+					return tokenFromLexer(DOTDOT, startPos, "..")
 				}
 				l.backup()
 			}
@@ -327,6 +366,8 @@ func (l *Lexer) Lex() Token {
 					return tokenFromLexer(DECR, startPos, "--")
 				} else if nextR == '=' {
 					return tokenFromLexer(SUB_AND_ASSIGN, startPos, "-=")
+				} else if nextR == '>' {
+					return tokenFromLexer(THIN_ARROW, startPos, "->")
 				}
 				l.backup()
 			}
@@ -338,6 +379,8 @@ func (l *Lexer) Lex() Token {
 				l.pos.column++
 				if nextR == '=' {
 					return tokenFromLexer(MUL_AND_ASSIGN, startPos, "*=")
+				} else if nextR == '*' {
+					return tokenFromLexer(POW, startPos, "**")
 				}
 				l.backup()
 			}
@@ -537,6 +580,13 @@ func (l *Lexer) lexNumber(tokenType TokenType) (TokenType, string) {
 	offset := 0
 
 	for {
+		if tokenType == INTEGER {
+			// 1..5 is a range, not the float 1. followed by .5
+			if next, err := l.reader.Peek(2); err == nil && string(next) == ".." {
+				return tokenType, lit
+			}
+		}
+
 		r, _, err := l.reader.ReadRune()
 		if err != nil {
 			if err == io.EOF {
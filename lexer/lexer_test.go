@@ -14,9 +14,9 @@ func TestLexer(t *testing.T) {
 		expected []lexer.Token
 	}{
 		{
-			input: `+ - * / % ++ -- += -= *= /= %= 
-			< <= << <<= <<< > >= >> >>= >>> == != = => &
-			&= && | |= || ^ ^= ~ ( ) { } [ ] ; : , . 
+			input: `+ - * / % ** ++ -- += -= *= /= %= 
+			< <= << <<= <<< > >= >> >>= >>> == != = => -> &
+			&= && | |= || ^ ^= ~ ( ) { } [ ] ; : ? , . .. ..= ...
 			// comment
 			/*
 			multiline
@@ -30,6 +30,7 @@ func TestLexer(t *testing.T) {
 				{Type: lexer.MUL, Literal: "*"},
 				{Type: lexer.DIV, Literal: "/"},
 				{Type: lexer.MOD, Literal: "%"},
+				{Type: lexer.POW, Literal: "**"},
 				{Type: lexer.INCR, Literal: "++"},
 				{Type: lexer.DECR, Literal: "--"},
 				{Type: lexer.ADD_AND_ASSIGN, Literal: "+="},
@@ -51,6 +52,7 @@ func TestLexer(t *testing.T) {
 				{Type: lexer.NOT_EQUALS, Literal: "!="},
 				{Type: lexer.ASSIGN, Literal: "="},
 				{Type: lexer.ARROW, Literal: "=>"},
+				{Type: lexer.THIN_ARROW, Literal: "->"},
 				{Type: lexer.BITWISE_AND, Literal: "&"},
 				{Type: lexer.BITWISE_AND_ASSIGN, Literal: "&="},
 				{Type: lexer.LOGICAL_AND, Literal: "&&"},
@@ -68,8 +70,12 @@ func TestLexer(t *testing.T) {
 				{Type: lexer.RIGHT_SQUARE_BRACKET, Literal: "]"},
 				{Type: lexer.SEMICOLON, Literal: ";"},
 				{Type: lexer.COLON, Literal: ":"},
+				{Type: lexer.QUESTION, Literal: "?"},
 				{Type: lexer.COMMA, Literal: ","},
 				{Type: lexer.DOT, Literal: "."},
+				{Type: lexer.DOTDOT, Literal: ".."},
+				{Type: lexer.DOTDOT_EQUAL, Literal: "..="},
+				{Type: lexer.ELLIPSIS, Literal: "..."},
 				{Type: lexer.LET, Literal: "let"},
 				{Type: lexer.IDENTIFIER, Literal: "x"},
 				{Type: lexer.ASSIGN, Literal: "="},
@@ -168,6 +174,7 @@ func TestLexNumber(t *testing.T) {
 		{input: ".0xFA", expected: lexer.Token{Type: lexer.FLOAT, Literal: ".0xFA"}},
 		{input: "0xFA.", expected: lexer.Token{Type: lexer.FLOAT, Literal: "0xFA."}},
 		{input: "1_000", expected: lexer.Token{Type: lexer.INTEGER, Literal: "1000"}},
+		{input: "1..5", expected: lexer.Token{Type: lexer.INTEGER, Literal: "1"}},
 	}
 
 	for tid, tt := range tests {
@@ -233,6 +240,7 @@ func TestLexKeywords(t *testing.T) {
 		expected lexer.Token
 	}{
 		{input: "let", expected: lexer.Token{Type: lexer.LET, Literal: "let"}},
+		{input: "mut", expected: lexer.Token{Type: lexer.MUT, Literal: "mut"}},
 		{input: "is", expected: lexer.Token{Type: lexer.IS, Literal: "is"}},
 		{input: "in", expected: lexer.Token{Type: lexer.IN, Literal: "in"}},
 		{input: "and", expected: lexer.Token{Type: lexer.LOGICAL_AND, Literal: "and"}},
@@ -240,10 +248,17 @@ func TestLexKeywords(t *testing.T) {
 		{input: "not", expected: lexer.Token{Type: lexer.LOGICAL_NOT, Literal: "not"}},
 		{input: "true", expected: lexer.Token{Type: lexer.TRUE, Literal: "true"}},
 		{input: "false", expected: lexer.Token{Type: lexer.FALSE, Literal: "false"}},
+		{input: "none", expected: lexer.Token{Type: lexer.NONE, Literal: "none"}},
 		{input: "if", expected: lexer.Token{Type: lexer.IF, Literal: "if"}},
 		{input: "else", expected: lexer.Token{Type: lexer.ELSE, Literal: "else"}},
 		{input: "return", expected: lexer.Token{Type: lexer.RETURN, Literal: "return"}},
+		{input: "defer", expected: lexer.Token{Type: lexer.DEFER, Literal: "defer"}},
+		{input: "with", expected: lexer.Token{Type: lexer.WITH, Literal: "with"}},
 		{input: "fn", expected: lexer.Token{Type: lexer.FN, Literal: "fn"}},
+		{input: "struct", expected: lexer.Token{Type: lexer.STRUCT, Literal: "struct"}},
+		{input: "union", expected: lexer.Token{Type: lexer.UNION, Literal: "union"}},
+		{input: "self", expected: lexer.Token{Type: lexer.SELF, Literal: "self"}},
+		{input: "type", expected: lexer.Token{Type: lexer.TYPE, Literal: "type"}},
 	}
 
 	for tid, tt := range tests {
@@ -1,8 +1,53 @@
 package object
 
+import "github.com/poolpOrg/julu/ast"
+
 type Environment struct {
-	store map[string]Object
-	outer *Environment
+	store   map[string]Object
+	mutable map[string]bool
+	outer   *Environment
+	frame   *Frame
+	file    string
+}
+
+// Frame is a function call in progress, frames are linked to the frame of
+// their caller to form the call stack.
+type Frame struct {
+	Function string
+	Call     *ast.CallExpression // nil for calls made by the interpreter
+	Caller   *Frame
+	Depth    int
+}
+
+// Call is a run of identical calls in a call stack.
+type Call struct {
+	Frame *Frame
+	Count int
+}
+
+// Calls returns the calls of the stack ending at f, outermost first. Runs of
+// identical calls, as made by a recursive function, are collapsed.
+func (f *Frame) Calls() []Call {
+	var calls []Call
+	for ; f != nil; f = f.Caller {
+		if n := len(calls); n != 0 && calls[n-1].Frame.Function == f.Function && calls[n-1].Frame.Call == f.Call {
+			calls[n-1].Count++
+			continue
+		}
+		calls = append(calls, Call{Frame: f, Count: 1})
+	}
+	for i, j := 0, len(calls)-1; i < j; i, j = i+1, j-1 {
+		calls[i], calls[j] = calls[j], calls[i]
+	}
+	return calls
+}
+
+// NewCallEnvironment returns the environment of a function call, enclosed in
+// the environment the function was defined in.
+func NewCallEnvironment(outer *Environment, frame *Frame) *Environment {
+	env := NewEnclosedEnvironment(outer)
+	env.frame = frame
+	return env
 }
 
 func NewEnclosedEnvironment(outer *Environment) *Environment {
@@ -11,9 +56,38 @@ func NewEnclosedEnvironment(outer *Environment) *Environment {
 	return env
 }
 
+// NewFileEnvironment returns the top level environment of the source file.
+func NewFileEnvironment(file string) *Environment {
+	env := NewEnvironment()
+	env.file = file
+	return env
+}
+
 func NewEnvironment() *Environment {
 	s := make(map[string]Object)
-	return &Environment{store: s}
+	m := make(map[string]bool)
+	return &Environment{store: s, mutable: m}
+}
+
+// Frame returns the function call the environment belongs to, nil at the top
+// level.
+func (e *Environment) Frame() *Frame {
+	for ; e != nil; e = e.outer {
+		if e.frame != nil {
+			return e.frame
+		}
+	}
+	return nil
+}
+
+// File returns the source file the environment belongs to, empty if unknown.
+func (e *Environment) File() string {
+	for ; e != nil; e = e.outer {
+		if e.file != "" {
+			return e.file
+		}
+	}
+	return ""
 }
 
 func (e *Environment) Get(name string) (Object, bool) {
@@ -24,7 +98,42 @@ func (e *Environment) Get(name string) (Object, bool) {
 	return obj, ok
 }
 
+// Set binds name to val in the current scope, the binding is immutable.
 func (e *Environment) Set(name string, val Object) Object {
 	e.store[name] = val
+	delete(e.mutable, name)
+	return val
+}
+
+// SetMutable binds name to val in the current scope, the binding can later
+// be updated with Assign.
+func (e *Environment) SetMutable(name string, val Object) Object {
+	e.store[name] = val
+	e.mutable[name] = true
 	return val
 }
+
+// IsMutable reports whether the binding of name in the innermost scope
+// declaring it is mutable.
+func (e *Environment) IsMutable(name string) bool {
+	if _, ok := e.store[name]; ok {
+		return e.mutable[name]
+	}
+	if e.outer != nil {
+		return e.outer.IsMutable(name)
+	}
+	return false
+}
+
+// Assign updates the binding of name in the innermost scope declaring it, it
+// reports false if no enclosing scope declares name.
+func (e *Environment) Assign(name string, val Object) bool {
+	if _, ok := e.store[name]; ok {
+		e.store[name] = val
+		return true
+	}
+	if e.outer != nil {
+		return e.outer.Assign(name, val)
+	}
+	return false
+}
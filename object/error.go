@@ -0,0 +1,85 @@
+package object
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Span locates a piece of source code, from Line:Column to the character
+// before EndLine:EndColumn.
+type Span struct {
+	Line      int
+	Column    int
+	EndLine   int
+	EndColumn int
+}
+
+func (s *Span) String() string {
+	if s.EndLine == s.Line {
+		return fmt.Sprintf("%d:%d-%d", s.Line, s.Column, s.EndColumn)
+	}
+	return fmt.Sprintf("%d:%d-%d:%d", s.Line, s.Column, s.EndLine, s.EndColumn)
+}
+
+// Error is a runtime error. File and Span locate the code that failed, when
+// known, and Stack is the call in progress at that point, nil at the top
+// level.
+type Error struct {
+	Message string
+	File    string
+	Span    *Span
+	Stack   *Frame
+}
+
+func (e *Error) Type() ObjectType { return ERROR_OBJ }
+func (e *Error) Inspect() string  { return "ERROR: " + e.Error() }
+
+// Error returns the message of the error prefixed with its position.
+func (e *Error) Error() string {
+	if e.Span == nil {
+		return e.Message
+	}
+	return fmt.Sprintf("[%d:%d] %s", e.Span.Line, e.Span.Column, e.Message)
+}
+
+// Traceback describes the error along with the calls that led to it, the
+// most recent call last:
+//
+//	traceback (most recent call last):
+//	  main
+//	  parse called at script.julu:12:14
+//	  digit called at script.julu:5:20 (3 times)
+//	script.julu:2:14-15: type mismatch: INTEGER + STRING
+func (e *Error) Traceback() string {
+	var out strings.Builder
+
+	if e.Stack != nil {
+		out.WriteString("traceback (most recent call last):\n")
+		for _, call := range e.Stack.Calls() {
+			out.WriteString("  " + call.Frame.Function)
+			if call.Frame.Call != nil {
+				pos := call.Frame.Call.Token.Position()
+				out.WriteString(" called at " + e.location(fmt.Sprintf("%d:%d", pos.Line(), pos.Column())))
+			}
+			if call.Count > 1 {
+				fmt.Fprintf(&out, " (%d times)", call.Count)
+			}
+			out.WriteString("\n")
+		}
+	}
+
+	if e.Span != nil {
+		out.WriteString(e.location(e.Span.String()) + ": ")
+	} else if e.File != "" {
+		out.WriteString(e.File + ": ")
+	}
+	out.WriteString(e.Message + "\n")
+	return out.String()
+}
+
+func (e *Error) location(position string) string {
+	if e.File == "" {
+		return position
+	}
+	return e.File + ":" + position
+}
@@ -0,0 +1,57 @@
+package object
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// File is a file opened by the open builtin, it is closed by close or at
+// the end of the with block managing it.
+type File struct {
+	Name   string
+	File   *os.File
+	Closed bool
+}
+
+func (f *File) Type() ObjectType { return FILE_OBJ }
+func (f *File) Inspect() string  { return fmt.Sprintf("file(%q)", f.Name) }
+
+func (f *File) Enter() Object {
+	if f.Closed {
+		return &Error{Message: fmt.Sprintf("file %q is closed", f.Name)}
+	}
+	return f
+}
+
+// Exit closes the file unless it was closed by the block.
+func (f *File) Exit() Object {
+	if f.Closed {
+		return nil
+	}
+	if err := f.Close(); err != nil {
+		return &Error{Message: err.Error()}
+	}
+	return nil
+}
+
+func (f *File) Close() error {
+	f.Closed = true
+	return f.File.Close()
+}
+
+func (f *File) Write(s string) (int, error) {
+	if f.Closed {
+		return 0, fmt.Errorf("file %q is closed", f.Name)
+	}
+	return f.File.WriteString(s)
+}
+
+// ReadAll reads the file from the current offset to its end.
+func (f *File) ReadAll() (string, error) {
+	if f.Closed {
+		return "", fmt.Errorf("file %q is closed", f.Name)
+	}
+	content, err := io.ReadAll(f.File)
+	return string(content), err
+}
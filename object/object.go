@@ -3,6 +3,7 @@ package object
 import (
 	"fmt"
 	"hash/fnv"
+	"strings"
 
 	"github.com/poolpOrg/julu/ast"
 )
@@ -12,8 +13,16 @@ type ObjectType string
 const (
 	VOID_OBJ         = "VOID"
 	INTEGER_OBJ      = "INTEGER"
+	INT8_OBJ         = "INT8"
+	INT16_OBJ        = "INT16"
+	INT32_OBJ        = "INT32"
+	UINT8_OBJ        = "UINT8"
+	UINT16_OBJ       = "UINT16"
+	UINT32_OBJ       = "UINT32"
+	UINT64_OBJ       = "UINT64"
 	FLOAT_OBJ        = "FLOAT"
 	BOOLEAN_OBJ      = "BOOLEAN"
+	CHAR_OBJ         = "CHAR"
 	NULL_OBJ         = "NULL"
 	STRING_OBJ       = "STRING"
 	RETURN_VALUE_OBJ = "RETURN_VALUE"
@@ -21,12 +30,68 @@ const (
 	FUNCTION_OBJ     = "FUNCTION"
 	BUILTIN_OBJ      = "BUILTIN"
 	ARRAY_OBJ        = "ARRAY"
+	TUPLE_OBJ        = "TUPLE"
+	RESULT_OBJ       = "RESULT"
+	OPTION_OBJ       = "OPTION"
+	FILE_OBJ         = "FILE"
+	STRUCT_OBJ       = "STRUCT"
+	UNION_OBJ        = "UNION"
+	TYPE_OBJ         = "TYPE"
 	HASH_OBJ         = "HASH"
+	RANGE_OBJ        = "RANGE"
 	CONTINUE_OBJ     = "CONTINUE"
 	BREAK_OBJ        = "BREAK"
 	DONE_OBJ         = "DONE"
 )
 
+// DeclaredTypes maps the type names usable in a declaration to the type of
+// the objects they hold.
+var DeclaredTypes = map[string]ObjectType{
+	"int":     INTEGER_OBJ,
+	"int64":   INTEGER_OBJ,
+	"int8":    INT8_OBJ,
+	"int16":   INT16_OBJ,
+	"int32":   INT32_OBJ,
+	"uint8":   UINT8_OBJ,
+	"uint16":  UINT16_OBJ,
+	"uint32":  UINT32_OBJ,
+	"uint64":  UINT64_OBJ,
+	"float":   FLOAT_OBJ,
+	"float32": FLOAT_OBJ,
+	"float64": FLOAT_OBJ,
+	"bool":    BOOLEAN_OBJ,
+	"char":    CHAR_OBJ,
+	"string":  STRING_OBJ,
+	"array":   ARRAY_OBJ,
+	"hash":    HASH_OBJ,
+	"result":  RESULT_OBJ,
+	"option":  OPTION_OBJ,
+}
+
+// NullableTypes are the declared types that also accept null, so that a
+// function can return a value and an optional error message.
+var NullableTypes = map[ObjectType]bool{
+	STRING_OBJ: true,
+	ARRAY_OBJ:  true,
+	HASH_OBJ:   true,
+}
+
+// IsBuiltinType reports whether name is the type of builtin values. Since
+// the type of a value of a struct, union or named type is the name of its
+// declaration, declared types can't take these names.
+func IsBuiltinType(name string) bool {
+	switch name {
+	case VOID_OBJ, INTEGER_OBJ, INT8_OBJ, INT16_OBJ, INT32_OBJ,
+		UINT8_OBJ, UINT16_OBJ, UINT32_OBJ, UINT64_OBJ, FLOAT_OBJ,
+		BOOLEAN_OBJ, CHAR_OBJ, NULL_OBJ, STRING_OBJ, RETURN_VALUE_OBJ,
+		ERROR_OBJ, FUNCTION_OBJ, BUILTIN_OBJ, ARRAY_OBJ, TUPLE_OBJ,
+		RESULT_OBJ, OPTION_OBJ, FILE_OBJ, STRUCT_OBJ, UNION_OBJ,
+		TYPE_OBJ, HASH_OBJ, RANGE_OBJ, CONTINUE_OBJ, BREAK_OBJ, DONE_OBJ:
+		return true
+	}
+	return false
+}
+
 type HashKey struct {
 	Type  ObjectType
 	Value uint64
@@ -54,6 +119,58 @@ func (i *Integer) Inspect() string  { return fmt.Sprintf("%d", i.Value) }
 func (i *Integer) Type() ObjectType { return INTEGER_OBJ }
 func (i *Integer) HashKey() HashKey { return HashKey{Type: i.Type(), Value: uint64(i.Value)} }
 
+// SizedInteger is an integer of fixed width and signedness, such as an int8
+// or a uint64. Value holds the low Width bits of the integer in two's
+// complement, so arithmetic on it wraps around like in C.
+type SizedInteger struct {
+	Value  uint64
+	Width  int
+	Signed bool
+}
+
+func NewSizedInteger(value uint64, width int, signed bool) *SizedInteger {
+	if width < 64 {
+		value &= 1<<width - 1
+	}
+	return &SizedInteger{Value: value, Width: width, Signed: signed}
+}
+
+// Int64 returns the value sign-extended to 64 bits for signed integers, and
+// reinterpreted as an int64 for unsigned ones.
+func (i *SizedInteger) Int64() int64 {
+	if !i.Signed {
+		return int64(i.Value)
+	}
+	shift := 64 - i.Width
+	return int64(i.Value<<shift) >> shift
+}
+
+func (i *SizedInteger) Inspect() string {
+	if i.Signed {
+		return fmt.Sprintf("%d", i.Int64())
+	}
+	return fmt.Sprintf("%d", i.Value)
+}
+func (i *SizedInteger) Type() ObjectType {
+	switch {
+	case i.Signed && i.Width == 8:
+		return INT8_OBJ
+	case i.Signed && i.Width == 16:
+		return INT16_OBJ
+	case i.Signed:
+		return INT32_OBJ
+	case i.Width == 8:
+		return UINT8_OBJ
+	case i.Width == 16:
+		return UINT16_OBJ
+	case i.Width == 32:
+		return UINT32_OBJ
+	default:
+		return UINT64_OBJ
+	}
+}
+func (i *SizedInteger) HashKey() HashKey { return HashKey{Type: i.Type(), Value: i.Value} }
+
 type Float struct {
 	Value float64
 }
@@ -75,6 +192,14 @@ func (b *Boolean) HashKey() HashKey {
 	return HashKey{Type: b.Type(), Value: value}
 }
 
+type Char struct {
+	Value rune
+}
+
+func (c *Char) Inspect() string  { return string(c.Value) }
+func (c *Char) Type() ObjectType { return CHAR_OBJ }
+func (c *Char) HashKey() HashKey { return HashKey{Type: c.Type(), Value: uint64(c.Value)} }
+
 type Null struct{}
 
 func (n *Null) Inspect() string  { return "null" }
@@ -99,23 +224,23 @@ type ReturnValue struct {
 func (rv *ReturnValue) Type() ObjectType { return RETURN_VALUE_OBJ }
 func (rv *ReturnValue) Inspect() string  { return rv.Value.Inspect() }
 
-type Error struct {
-	Message string
-}
-
-func (e *Error) Type() ObjectType { return ERROR_OBJ }
-func (e *Error) Inspect() string  { return "ERROR: " + e.Message }
-
+// Function is a function declared in julu. Owner is the name of the type a
+// method is bound to, it is empty for plain functions.
 type Function struct {
-	Name       *ast.Identifier
-	Parameters []*ast.Identifier
-	Body       *ast.BlockStatement
-	Env        *Environment
+	Name        *ast.Identifier
+	Owner       string
+	Parameters  []*ast.Parameter
+	ReturnTypes []*ast.Identifier
+	Body        *ast.BlockStatement
+	Env         *Environment
 }
 
 func (f *Function) Type() ObjectType { return FUNCTION_OBJ }
 func (f *Function) Inspect() string {
-	if f.Name != nil {
+	if f.Owner != "" {
+		return fmt.Sprintf("fn %s.%s(%s) {\n%s\n}",
+			f.Owner, f.Name.String(), f.Parameters, f.Body.String())
+	} else if f.Name != nil {
 		return fmt.Sprintf("fn %s(%s) {\n%s\n}",
 			f.Name.String(), f.Parameters, f.Body.String())
 	} else {
@@ -124,14 +249,51 @@ func (f *Function) Inspect() string {
 	}
 }
 
-type BuiltinFunction func(args ...Object) Object
+// BoundMethod is a method looked up on a value, the value is passed to the
+// method as self when it is called. Its type names the method along with the
+// type owning it, File.close.
+type BoundMethod struct {
+	Receiver Object
+	Method   *Function
+}
+
+func (m *BoundMethod) Type() ObjectType {
+	return ObjectType(m.Method.Owner + "." + m.Method.Name.Value)
+}
+func (m *BoundMethod) Inspect() string { return m.Method.Inspect() }
+
+// Context is the interpreter running a builtin, it lets the builtin call
+// back into julu.
+type Context interface {
+	// Call calls fn, a function or a builtin, with args and returns its
+	// result, errors included.
+	Call(fn Object, args ...Object) Object
+}
+
+type BuiltinFunction func(ctx Context, args ...Object) Object
+
+// Builtin is a function implemented by the interpreter. Parameters names the
+// positional arguments that can also be passed by name, it is nil for
+// builtins that don't accept named arguments.
 type Builtin struct {
-	Fn BuiltinFunction
+	Name       string
+	Parameters []string
+	Fn         BuiltinFunction
 }
 
 func (b *Builtin) Type() ObjectType { return BUILTIN_OBJ }
 func (b *Builtin) Inspect() string  { return "builtin function" }
 
+// Resource is a builtin value that can be managed by a with block: Enter is
+// called when the block is entered and returns the value bound by the block,
+// Exit is called when it exits, whatever the reason. Both return an error
+// or nil.
+type Resource interface {
+	Object
+	Enter() Object
+	Exit() Object
+}
+
 type Array struct {
 	Elements []Object
 }
@@ -148,6 +310,181 @@ func (ao *Array) Inspect() string {
 	return "[" + out + "]"
 }
 
+// Tuple holds the values returned together by a function, it is unpacked by
+// a destructuring let.
+type Tuple struct {
+	Elements []Object
+}
+
+func (t *Tuple) Type() ObjectType { return TUPLE_OBJ }
+func (t *Tuple) Inspect() string {
+	var out string
+	for i, e := range t.Elements {
+		if i != 0 {
+			out += ", "
+		}
+		out += e.Inspect()
+	}
+	return "(" + out + ")"
+}
+
+// Result is the outcome of an operation that can fail, ok(Value) when Ok is
+// set and err(Value) otherwise.
+type Result struct {
+	Ok    bool
+	Value Object
+}
+
+func (r *Result) Type() ObjectType { return RESULT_OBJ }
+func (r *Result) Inspect() string {
+	if r.Ok {
+		return "ok(" + r.Value.Inspect() + ")"
+	}
+	return "err(" + r.Value.Inspect() + ")"
+}
+
+// Option is a value that may be absent, some(Value), or none when Value is
+// nil.
+type Option struct {
+	Value Object
+}
+
+func (o *Option) Type() ObjectType { return OPTION_OBJ }
+func (o *Option) Inspect() string {
+	if o.Value == nil {
+		return "none"
+	}
+	return "some(" + o.Value.Inspect() + ")"
+}
+
+// StructType is a struct declaration, Fields lists its fields in declaration
+// order and Env is the environment their types are resolved in. Methods
+// holds the methods bound to the struct.
+type StructType struct {
+	Name    string
+	Fields  []*ast.Parameter
+	Env     *Environment
+	Methods map[string]*Function
+}
+
+func (s *StructType) Type() ObjectType { return STRUCT_OBJ }
+func (s *StructType) Inspect() string {
+	fields := make([]string, len(s.Fields))
+	for i, field := range s.Fields {
+		fields[i] = field.String()
+	}
+	return "struct " + s.Name + " { " + strings.Join(fields, ", ") + " }"
+}
+
+// Field returns the declaration of the field name, or nil if there is none.
+func (s *StructType) Field(name string) *ast.Parameter {
+	for _, field := range s.Fields {
+		if field.Name.Value == name {
+			return field
+		}
+	}
+	return nil
+}
+
+// Struct is a value of a struct type, its type is the name of the struct.
+type Struct struct {
+	Definition *StructType
+	Fields     map[string]Object
+}
+
+func (s *Struct) Type() ObjectType { return ObjectType(s.Definition.Name) }
+func (s *Struct) Inspect() string {
+	fields := make([]string, len(s.Definition.Fields))
+	for i, field := range s.Definition.Fields {
+		fields[i] = field.Name.Value + ": " + s.Fields[field.Name.Value].Inspect()
+	}
+	return s.Definition.Name + "{" + strings.Join(fields, ", ") + "}"
+}
+
+// UnionType is a union declaration, Env is the environment the types of the
+// fields of its variants are resolved in and Methods holds the methods bound
+// to the union.
+type UnionType struct {
+	Name     string
+	Variants []*ast.UnionVariant
+	Env      *Environment
+	Methods  map[string]*Function
+}
+
+func (u *UnionType) Type() ObjectType { return UNION_OBJ }
+func (u *UnionType) Inspect() string {
+	variants := make([]string, len(u.Variants))
+	for i, variant := range u.Variants {
+		variants[i] = variant.String()
+	}
+	return "union " + u.Name + " { " + strings.Join(variants, ", ") + " }"
+}
+
+// Variant returns the declaration of the variant name, or nil if there is
+// none.
+func (u *UnionType) Variant(name string) *ast.UnionVariant {
+	for _, variant := range u.Variants {
+		if variant.Name.Value == name {
+			return variant
+		}
+	}
+	return nil
+}
+
+// Union is a value of a union type, it holds the values of the fields of
+// its variant. Its type is the name of the union.
+type Union struct {
+	Definition *UnionType
+	Variant    *ast.UnionVariant
+	Values     []Object
+}
+
+func (u *Union) Type() ObjectType { return ObjectType(u.Definition.Name) }
+func (u *Union) Inspect() string {
+	if u.Variant.Fields == nil {
+		return u.Variant.Name.Value
+	}
+	values := make([]string, len(u.Values))
+	for i, value := range u.Values {
+		values[i] = value.Inspect()
+	}
+	return u.Variant.Name.Value + "(" + strings.Join(values, ", ") + ")"
+}
+
+// TypeAlias is another name for the type Target, resolved in Env. Values of
+// the alias and of its target are the same type.
+type TypeAlias struct {
+	Name   string
+	Target string
+	Env    *Environment
+}
+
+func (t *TypeAlias) Type() ObjectType { return TYPE_OBJ }
+func (t *TypeAlias) Inspect() string  { return "type " + t.Name + " = " + t.Target }
+
+// NamedType is a distinct type represented by its Underlying type, resolved
+// in Env. Its values don't mix with values of the underlying type without a
+// cast. Methods holds the methods bound to the type.
+type NamedType struct {
+	Name       string
+	Underlying string
+	Env        *Environment
+	Methods    map[string]*Function
+}
+
+func (n *NamedType) Type() ObjectType { return TYPE_OBJ }
+func (n *NamedType) Inspect() string  { return "type " + n.Name + " " + n.Underlying }
+
+// Named is a value of a named type, Value holds its representation. Its type
+// is the name of the named type.
+type Named struct {
+	Definition *NamedType
+	Value      Object
+}
+
+func (n *Named) Type() ObjectType { return ObjectType(n.Definition.Name) }
+func (n *Named) Inspect() string  { return n.Value.Inspect() }
+
 type HashPair struct {
 	Key   Object
 	Value Object
@@ -165,12 +502,33 @@ func (h *Hash) Inspect() string {
 	return "{" + out + "}"
 }
 
-type Break struct{}
+type Range struct {
+	Start int64
+	End   int64
+	Step  int64
+}
+
+func (r *Range) Type() ObjectType { return RANGE_OBJ }
+func (r *Range) Inspect() string {
+	if r.Step == 1 {
+		return fmt.Sprintf("range(%d, %d)", r.Start, r.End)
+	}
+	return fmt.Sprintf("range(%d, %d, %d)", r.Start, r.End, r.Step)
+}
+
+// Break interrupts the loop named Label, or the innermost loop if Label is
+// empty. Value is what the loop evaluates to, nil if break had no value.
+type Break struct {
+	Label string
+	Value Object
+}
 
 func (b *Break) Type() ObjectType { return BREAK_OBJ }
 func (b *Break) Inspect() string  { return "break" }
 
-type Continue struct{}
+type Continue struct {
+	Label string
+}
 
 func (c *Continue) Type() ObjectType { return CONTINUE_OBJ }
 func (c *Continue) Inspect() string  { return "continue" }
@@ -3,6 +3,7 @@ package parser
 import (
 	"fmt"
 	"strconv"
+	"unicode/utf8"
 
 	"github.com/poolpOrg/julu/ast"
 	"github.com/poolpOrg/julu/lexer"
@@ -25,6 +26,21 @@ type Parser struct {
 	infixParseFns  map[lexer.TokenType]infixParseFn
 
 	entryPoint ast.Expression
+
+	scopes []map[string]bool
+	labels []string
+	// loops is the number of loops enclosing the current statement in the
+	// current function, break and continue are only valid inside one
+	loops int
+
+	// noStructLiterals is set while parsing the expression heading a block,
+	// where an identifier followed by { is not a struct literal
+	noStructLiterals bool
+	// clauseBrace is the { following an identifier that ends the expression
+	// heading a block, fieldLabel is set when a label opens that block: it
+	// may be the first field of an unparenthesized struct literal
+	clauseBrace lexer.Token
+	fieldLabel  bool
 }
 
 func New(l *lexer.Lexer) *Parser {
@@ -34,16 +50,21 @@ func New(l *lexer.Lexer) *Parser {
 		prefixParseFns: make(map[lexer.TokenType]prefixParseFn),
 		infixParseFns:  make(map[lexer.TokenType]infixParseFn),
 	}
+	p.enterScope()
 
 	// Read two tokens, so curToken and peekToken are both set
 	p.nextToken()
 	p.nextToken()
 
 	p.registerPrefix(lexer.IDENTIFIER, p.parseIdentifier)
+	p.registerPrefix(lexer.SELF, p.parseIdentifier)
+	p.registerPrefix(lexer.TYPE, p.parseIdentifier)
 	p.registerPrefix(lexer.INTEGER, p.parseIntegerLiteral)
 	p.registerPrefix(lexer.FLOAT, p.parseFloatLiteral)
 	p.registerPrefix(lexer.STRING, p.parseStringLiteral)
+	p.registerPrefix(lexer.RUNE, p.parseCharLiteral)
 	p.registerPrefix(lexer.NULL, p.parseNull)
+	p.registerPrefix(lexer.NONE, p.parseNone)
 	p.registerPrefix(lexer.FSTRING, p.parseFStringLiteral)
 	p.registerPrefix(lexer.LOGICAL_NOT, p.parsePrefixExpression) // !x
 	p.registerPrefix(lexer.BITWISE_NOT, p.parsePrefixExpression) // !x
@@ -60,13 +81,15 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerPrefix(lexer.UNTIL, p.parseUntilStatement)
 	p.registerPrefix(lexer.FOR, p.parseForStatement)
 	p.registerPrefix(lexer.MATCH, p.parseMatchExpression)
+	p.registerPrefix(lexer.WITH, p.parseWithStatement)
 
-	p.registerInfix(lexer.AS, p.parseInfixExpression)
+	p.registerInfix(lexer.AS, p.parseCastExpression)
 	p.registerInfix(lexer.ADD, p.parseInfixExpression)
 	p.registerInfix(lexer.SUB, p.parseInfixExpression)
 	p.registerInfix(lexer.MUL, p.parseInfixExpression)
 	p.registerInfix(lexer.DIV, p.parseInfixExpression)
 	p.registerInfix(lexer.MOD, p.parseInfixExpression)
+	p.registerInfix(lexer.POW, p.parseInfixExpression)
 
 	p.registerInfix(lexer.BITWISE_AND, p.parseInfixExpression)
 	p.registerInfix(lexer.BITWISE_OR, p.parseInfixExpression)
@@ -86,8 +109,24 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerInfix(lexer.GREATER_THAN, p.parseInfixExpression)
 	p.registerInfix(lexer.LESSER_OR_EQUAL, p.parseInfixExpression)
 	p.registerInfix(lexer.GREATER_OR_EQUAL, p.parseInfixExpression)
+	p.registerInfix(lexer.ASSIGN, p.parseAssignExpression)
+	p.registerInfix(lexer.ADD_AND_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(lexer.SUB_AND_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(lexer.MUL_AND_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(lexer.DIV_AND_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(lexer.MOD_AND_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(lexer.BITWISE_AND_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(lexer.BITWISE_OR_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(lexer.BITWISE_XOR_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(lexer.LSHIFT_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(lexer.RSHIFT_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(lexer.INCR, p.parsePostfixExpression) // x++
+	p.registerInfix(lexer.DECR, p.parsePostfixExpression) // x--
+	p.registerInfix(lexer.QUESTION, p.parseTryExpression) // f(x)?
+
 	p.registerInfix(lexer.LEFT_PARENTHESIS, p.parseCallExpression) // myFunction(x)
 	p.registerInfix(lexer.LEFT_SQUARE_BRACKET, p.parseIndexExpression)
+	p.registerInfix(lexer.DOT, p.parseFieldExpression)
 
 	return p
 }
@@ -156,12 +195,31 @@ func (p *Parser) parseStatement() ast.Statement {
 		ret = p.parseDoneStatement()
 	case lexer.RETURN:
 		ret = p.parseReturnStatement()
+	case lexer.DEFER:
+		ret = p.parseDeferStatement()
+	case lexer.STRUCT:
+		ret = p.parseStructStatement()
+	case lexer.UNION:
+		ret = p.parseUnionStatement()
+	case lexer.TYPE:
+		// type is also the builtin returning the type of a value
+		if p.peekTokenIs(lexer.IDENTIFIER) {
+			ret = p.parseTypeStatement()
+		} else {
+			ret = p.parseExpressionStatement()
+		}
 	case lexer.BREAK:
 		ret = p.parseBreakStatement()
 	case lexer.CONTINUE:
 		ret = p.parseContinueStatement()
 	default:
-		ret = p.parseExpressionStatement()
+		if p.curTokenIs(lexer.IDENTIFIER) && p.peekTokenIs(lexer.COLON) {
+			ret = p.parseLabeledStatement()
+		} else if p.curTokenIs(lexer.IDENTIFIER) && p.peekTokenIs(lexer.ARROW) {
+			ret = p.parseMethodBlockStatement()
+		} else {
+			ret = p.parseExpressionStatement()
+		}
 	}
 
 	if p.peekTokenIs(lexer.SEMICOLON) {
@@ -174,15 +232,53 @@ func (p *Parser) parseStatement() ast.Statement {
 func (p *Parser) parseLetStatement() *ast.LetStatement {
 	stmt := ast.NewLetStatement(p.curToken)
 
+	if p.peekTokenIs(lexer.MUT) {
+		p.nextToken()
+		stmt.Mutable = true
+	}
+
 	if !p.expectPeek(lexer.IDENTIFIER) {
 		return nil
 	}
 	stmt.Name = ast.NewIdentifier(p.curToken)
 
+	if p.peekTokenIs(lexer.COMMA) {
+		return p.parseDestructuringLetStatement(stmt)
+	}
+
 	if p.peekTokenIs(lexer.COLON) {
 		p.nextToken()
 		p.nextToken()
-		stmt.Type = ast.NewIdentifier(p.curToken)
+		stmt.Type = p.parseTypeName()
+	}
+
+	if !p.expectPeek(lexer.ASSIGN) {
+		return nil
+	}
+	p.nextToken()
+
+	if p.curTokenIs(lexer.IDENTIFIER) && p.peekTokenIs(lexer.COLON) {
+		stmt.Value = p.parseLabeledLoop()
+	} else {
+		stmt.Value = p.parseExpression(LOWEST)
+	}
+	p.declare(stmt.Name.Value, stmt.Mutable)
+
+	return stmt
+}
+
+// parseDestructuringLetStatement parses the names and values of
+// let a, b = f() once the first name has been parsed.
+func (p *Parser) parseDestructuringLetStatement(stmt *ast.LetStatement) *ast.LetStatement {
+	stmt.Names = append(stmt.Names, stmt.Name)
+	stmt.Name = nil
+
+	for p.peekTokenIs(lexer.COMMA) {
+		p.nextToken()
+		if !p.expectPeek(lexer.IDENTIFIER) {
+			return nil
+		}
+		stmt.Names = append(stmt.Names, ast.NewIdentifier(p.curToken))
 	}
 
 	if !p.expectPeek(lexer.ASSIGN) {
@@ -190,7 +286,10 @@ func (p *Parser) parseLetStatement() *ast.LetStatement {
 	}
 	p.nextToken()
 
-	stmt.Value = p.parseExpression(LOWEST)
+	stmt.Value = p.parseTupleExpression()
+	for _, name := range stmt.Names {
+		p.declare(name.Value, stmt.Mutable)
+	}
 
 	return stmt
 }
@@ -200,16 +299,142 @@ func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 
 	p.nextToken()
 
-	stmt.ReturnValue = p.parseExpression(LOWEST)
+	stmt.ReturnValue = p.parseTupleExpression()
+	return stmt
+}
+
+func (p *Parser) parseDeferStatement() *ast.DeferStatement {
+	stmt := ast.NewDeferStatement(p.curToken)
+
+	p.nextToken()
+
+	stmt.Call = p.parseExpression(LOWEST)
 	return stmt
 }
 
+// parseTupleExpression parses one expression, or a tuple of comma separated
+// expressions: return x, err
+func (p *Parser) parseTupleExpression() ast.Expression {
+	token := p.curToken
+	first := p.parseExpression(LOWEST)
+	if !p.peekTokenIs(lexer.COMMA) {
+		return first
+	}
+
+	tuple := ast.NewTupleLiteral(token)
+	tuple.Elements = append(tuple.Elements, first)
+	for p.peekTokenIs(lexer.COMMA) {
+		p.nextToken()
+		p.nextToken()
+		tuple.Elements = append(tuple.Elements, p.parseExpression(LOWEST))
+	}
+	return tuple
+}
+
+// parseBreakStatement parses break, optionally followed by the label of the
+// loop to exit and by the value the loop evaluates to:
+//
+//	break outer found
+//
+// A name following break is a label only if it names an enclosing loop, and
+// the value must start on the same line as break.
 func (p *Parser) parseBreakStatement() *ast.BreakStatement {
-	return ast.NewBreakStatement(p.curToken)
+	stmt := ast.NewBreakStatement(p.curToken)
+	p.checkInLoop()
+
+	if p.peekTokenIs(lexer.IDENTIFIER) && p.isLabel(p.peekToken.Literal) {
+		p.nextToken()
+		stmt.Label = ast.NewIdentifier(p.curToken)
+	}
+
+	if p.peekStartsValue() {
+		p.nextToken()
+		stmt.Value = p.parseExpression(LOWEST)
+	}
+	return stmt
 }
 
 func (p *Parser) parseContinueStatement() *ast.ContinueStatement {
-	return ast.NewContinueStatement(p.curToken)
+	stmt := ast.NewContinueStatement(p.curToken)
+	p.checkInLoop()
+
+	if p.peekTokenIs(lexer.IDENTIFIER) && p.peekStartsValue() {
+		p.nextToken()
+		if !p.isLabel(p.curToken.Literal) {
+			p.pushError(fmt.Sprintf("[%d:%d] unknown loop label: %s",
+				p.curToken.Position().Line(), p.curToken.Position().Column(), p.curToken.Literal))
+		}
+		stmt.Label = ast.NewIdentifier(p.curToken)
+	}
+	return stmt
+}
+
+// peekStartsValue reports whether the next token begins an operand of the
+// current statement rather than the next statement.
+func (p *Parser) peekStartsValue() bool {
+	switch p.peekToken.Type {
+	case lexer.SEMICOLON, lexer.RIGHT_CURLY_BRACKET, lexer.EOF:
+		return false
+	}
+	return p.peekToken.Position().Line() == p.curToken.Position().Line()
+}
+
+func (p *Parser) parseLabeledStatement() *ast.ExpressionStatement {
+	stmt := ast.NewExpressionStatement(p.curToken)
+	stmt.Expression = p.parseLabeledLoop()
+	return stmt
+}
+
+// parseLabeledLoop parses a loop preceded by a label, outer: loop { ... }
+func (p *Parser) parseLabeledLoop() ast.Expression {
+	label := ast.NewIdentifier(p.curToken)
+	fieldLabel := p.fieldLabel
+	p.fieldLabel = false
+	p.nextToken()
+	p.nextToken()
+
+	switch p.curToken.Type {
+	case lexer.LOOP, lexer.WHILE, lexer.UNTIL, lexer.FOR:
+	default:
+		if fieldLabel {
+			p.pushError(fmt.Sprintf("[%d:%d] struct literal in condition must be parenthesized",
+				p.clauseBrace.Position().Line(), p.clauseBrace.Position().Column()))
+			return nil
+		}
+		p.pushError(fmt.Sprintf("[%d:%d] expected a loop after label %s, got %s",
+			p.curToken.Position().Line(), p.curToken.Position().Column(), label.Value, p.curToken.Type))
+		return nil
+	}
+
+	p.labels = append(p.labels, label.Value)
+	expression := p.parseExpression(LOWEST)
+	p.labels = p.labels[:len(p.labels)-1]
+
+	switch loop := expression.(type) {
+	case *ast.LoopStatement:
+		loop.Label = label
+	case *ast.ForStatement:
+		loop.Label = label
+	}
+	return expression
+}
+
+// checkInLoop reports an error if the current break or continue statement
+// is not inside a loop of the current function.
+func (p *Parser) checkInLoop() {
+	if p.loops == 0 {
+		p.pushError(fmt.Sprintf("[%d:%d] %s outside a loop",
+			p.curToken.Position().Line(), p.curToken.Position().Column(), p.curToken.Literal))
+	}
+}
+
+func (p *Parser) isLabel(name string) bool {
+	for _, label := range p.labels {
+		if label == name {
+			return true
+		}
+	}
+	return false
 }
 
 func (p *Parser) parseDoneStatement() *ast.DoneStatement {
@@ -252,6 +477,12 @@ func (p *Parser) parseExpression(precedence int) ast.Expression {
 }
 
 func (p *Parser) parseIdentifier() ast.Expression {
+	if p.peekTokenIs(lexer.LEFT_CURLY_BRACKET) {
+		if !p.noStructLiterals {
+			return p.parseStructLiteral()
+		}
+		p.clauseBrace = p.peekToken
+	}
 	return ast.NewIdentifier(p.curToken)
 }
 
@@ -259,41 +490,67 @@ func (p *Parser) parseStringLiteral() ast.Expression {
 	return ast.NewStringLiteral(p.curToken)
 }
 
+func (p *Parser) parseCharLiteral() ast.Expression {
+	value, size := utf8.DecodeRuneInString(p.curToken.Literal)
+	if value == utf8.RuneError || size != len(p.curToken.Literal) {
+		p.pushError(fmt.Sprintf("could not parse %q as char", p.curToken.Literal))
+		return nil
+	}
+	return ast.NewCharLiteral(p.curToken, value)
+}
+
 func (p *Parser) parseFStringLiteral() ast.Expression {
 	return ast.NewFStringLiteral(p.curToken)
 }
 
 func (p *Parser) parseIntegerLiteral() ast.Expression {
-	value, err := strconv.ParseInt(p.curToken.Literal, 0, 64)
-	if err != nil {
-		p.pushError(fmt.Sprintf("could not parse %q as integer", p.curToken.Literal))
+	expr := p.newIntegerLiteral()
+	if expr == nil {
 		return nil
 	}
-	expr := ast.NewIntegerLiteral(p.curToken, value)
 
 	if p.peekToken.Type == lexer.COLON {
 		p.nextToken()
 		p.nextToken()
-		expr.Cast = ast.NewIdentifier(p.curToken)
+		expr.Cast = p.parseTypeName()
 	}
 	return expr
 }
 
+func (p *Parser) newIntegerLiteral() *ast.IntegerLiteral {
+	if value, err := strconv.ParseInt(p.curToken.Literal, 0, 64); err == nil {
+		return ast.NewIntegerLiteral(p.curToken, value)
+	} else if value, err := strconv.ParseUint(p.curToken.Literal, 0, 64); err == nil {
+		// like in C, literals too large for an int64 are uint64
+		expr := ast.NewIntegerLiteral(p.curToken, int64(value))
+		expr.Unsigned = true
+		return expr
+	}
+	p.pushError(fmt.Sprintf("could not parse %q as integer", p.curToken.Literal))
+	return nil
+}
+
 func (p *Parser) parseFloatLiteral() ast.Expression {
-	value, err := strconv.ParseFloat(p.curToken.Literal, 64)
-	if err != nil {
-		p.pushError(fmt.Sprintf("could not parse %q as float", p.curToken.Literal))
+	expr := p.newFloatLiteral()
+	if expr == nil {
 		return nil
 	}
-	expr := ast.NewFloatLiteral(p.curToken, value)
 
 	if p.peekToken.Type == lexer.COLON {
 		p.nextToken()
 		p.nextToken()
-		expr.Cast = ast.NewIdentifier(p.curToken)
+		expr.Cast = p.parseTypeName()
 	}
 	return expr
+}
 
+func (p *Parser) newFloatLiteral() *ast.FloatLiteral {
+	value, err := strconv.ParseFloat(p.curToken.Literal, 64)
+	if err != nil {
+		p.pushError(fmt.Sprintf("could not parse %q as float", p.curToken.Literal))
+		return nil
+	}
+	return ast.NewFloatLiteral(p.curToken, value)
 }
 
 func (p *Parser) parsePrefixExpression() ast.Expression {
@@ -310,18 +567,99 @@ func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 	expression := ast.NewInfixExpression(p.curToken, left)
 
 	precedence := p.curPrecedence()
+	if p.curTokenIs(lexer.POW) {
+		// ** is right-associative, 2 ** 3 ** 2 is 2 ** (3 ** 2)
+		precedence--
+	}
 	p.nextToken()
 	expression.Right = p.parseExpression(precedence)
 
 	return expression
 }
 
+func (p *Parser) parseAssignExpression(left ast.Expression) ast.Expression {
+	if !p.checkAssignTarget(left) {
+		return nil
+	}
+	p.checkMutable(left)
+	expression := ast.NewAssignExpression(p.curToken, left)
+
+	// assignments are right-associative, a = b = 1 is a = (b = 1)
+	precedence := p.curPrecedence() - 1
+	p.nextToken()
+	expression.Value = p.parseExpression(precedence)
+
+	return expression
+}
+
+func (p *Parser) parsePostfixExpression(left ast.Expression) ast.Expression {
+	if !p.checkAssignTarget(left) {
+		return nil
+	}
+	p.checkMutable(left)
+	return ast.NewAssignExpression(p.curToken, left)
+}
+
+func (p *Parser) parseTryExpression(left ast.Expression) ast.Expression {
+	return ast.NewTryExpression(p.curToken, left)
+}
+
+func (p *Parser) checkAssignTarget(target ast.Expression) bool {
+	if target == nil {
+		return false
+	}
+	switch target.(type) {
+	case *ast.Identifier, *ast.IndexExpression, *ast.FieldExpression:
+		return true
+	}
+	p.pushError(fmt.Sprintf("[%d:%d] cannot assign to %s",
+		p.curToken.Position().Line(), p.curToken.Position().Column(), target.String()))
+	return false
+}
+
+func (p *Parser) parseCastExpression(left ast.Expression) ast.Expression {
+	expression := ast.NewCastExpression(p.curToken, left)
+
+	p.nextToken()
+	expression.Type = p.parseTypeName()
+	if expression.Type == nil {
+		return nil
+	}
+
+	return expression
+}
+
+// parseTypeName parses the type at the current token: an identifier such as
+// int, bool or the name of a struct, one of the sized numeric keywords, or
+// [T] for an array of T.
+func (p *Parser) parseTypeName() *ast.Identifier {
+	switch p.curToken.Type {
+	case lexer.LEFT_SQUARE_BRACKET:
+		token := p.curToken
+		p.nextToken()
+		elem := p.parseTypeName()
+		if elem == nil || !p.expectPeek(lexer.RIGHT_SQUARE_BRACKET) {
+			return nil
+		}
+		token.Literal = "[" + elem.Value + "]"
+		return ast.NewIdentifier(token)
+	case lexer.IDENTIFIER,
+		lexer.INT8, lexer.INT16, lexer.INT32, lexer.INT64,
+		lexer.UINT8, lexer.UINT16, lexer.UINT32, lexer.UINT64,
+		lexer.FLOAT32, lexer.FLOAT64:
+		return ast.NewIdentifier(p.curToken)
+	default:
+		p.pushError(fmt.Sprintf("expected type name, got %s instead", p.curToken.Type))
+		return nil
+	}
+}
+
 func (p *Parser) parseBoolean() ast.Expression {
 	expr := ast.NewBoolean(p.curToken, p.curTokenIs(lexer.TRUE))
 	if p.peekToken.Type == lexer.COLON {
 		p.nextToken()
 		p.nextToken()
-		expr.Cast = ast.NewIdentifier(p.curToken)
+		expr.Cast = p.parseTypeName()
 	}
 	return expr
 }
@@ -330,7 +668,12 @@ func (p *Parser) parseNull() ast.Expression {
 	return ast.NewNull(p.curToken)
 }
 
+func (p *Parser) parseNone() ast.Expression {
+	return ast.NewNone(p.curToken)
+}
+
 func (p *Parser) parseGroupedExpression() ast.Expression {
+	defer p.allowStructLiterals(true)()
 	p.nextToken()
 
 	exp := p.parseExpression(LOWEST)
@@ -346,7 +689,7 @@ func (p *Parser) parseIfExpression() ast.Expression {
 	expression := ast.NewIfExpression(p.curToken)
 
 	p.nextToken()
-	expression.Condition = p.parseExpression(LOWEST)
+	expression.Condition = p.parseClauseExpression()
 
 	if !p.peekTokenIs(lexer.LEFT_CURLY_BRACKET) && !p.peekTokenIs(lexer.ARROW) {
 		return nil
@@ -370,7 +713,6 @@ func (p *Parser) parseIfExpression() ast.Expression {
 		p.nextToken()
 		expression.Alternative = p.parseBlockStatement()
 	}
-	p.nextToken()
 
 	return expression
 }
@@ -379,6 +721,10 @@ func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 	block := ast.NewBlockStatement(p.curToken)
 	p.nextToken()
 
+	// like in the evaluator, a block shares the scope around it unless it
+	// is the body of a construct opening a scope, such as a function
+	defer p.allowStructLiterals(true)()
+
 	if block.Token.Type == lexer.ARROW {
 		stmt := p.parseStatement()
 		if stmt != nil {
@@ -387,7 +733,8 @@ func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 		return block
 	}
 
-	for !p.curTokenIs(lexer.RIGHT_CURLY_BRACKET) {
+	p.fieldLabel = block.Token == p.clauseBrace && p.curTokenIs(lexer.IDENTIFIER) && p.peekTokenIs(lexer.COLON)
+	for !p.curTokenIs(lexer.RIGHT_CURLY_BRACKET) && !p.curTokenIs(lexer.EOF) {
 		stmt := p.parseStatement()
 		if stmt != nil {
 			block.Statements = append(block.Statements, stmt)
@@ -404,11 +751,30 @@ func (p *Parser) parseFunctionLiteral() ast.Expression {
 	if p.peekTokenIs(lexer.IDENTIFIER) {
 		p.nextToken()
 		expression.Name = ast.NewIdentifier(p.curToken)
+		p.declare(expression.Name.Value, false)
 	}
 
+	p.enterScope()
+	defer p.leaveScope()
+
+	// break and continue can't interrupt the loops of the caller
+	labels, loops := p.labels, p.loops
+	p.labels, p.loops = nil, 0
+	defer func() { p.labels, p.loops = labels, loops }()
+
 	if p.peekTokenIs(lexer.LEFT_PARENTHESIS) {
 		p.nextToken()
 		expression.Parameters = p.parseFunctionParameters()
+		// self can be assigned so that methods can update the fields of
+		// the value they are called on, even one bound with a plain let
+		for _, param := range expression.Parameters {
+			p.declare(param.Name.Value, param.Name.Token.Type == lexer.SELF)
+		}
+	}
+
+	if p.peekTokenIs(lexer.THIN_ARROW) {
+		p.nextToken()
+		expression.ReturnTypes = p.parseReturnTypes()
 	}
 
 	if !p.peekTokenIs(lexer.ARROW) && !p.peekTokenIs(lexer.LEFT_CURLY_BRACKET) {
@@ -421,31 +787,105 @@ func (p *Parser) parseFunctionLiteral() ast.Expression {
 	return expression
 }
 
-func (p *Parser) parseFunctionParameters() []*ast.Identifier {
-	identifiers := []*ast.Identifier{}
+func (p *Parser) parseFunctionParameters() []*ast.Parameter {
+	parameters := []*ast.Parameter{}
 
 	if p.peekTokenIs(lexer.RIGHT_PARENTHESIS) {
 		p.nextToken()
-		return identifiers
+		return parameters
 	}
 
 	p.nextToken()
+	for {
+		param := p.parseFunctionParameter()
+		if len(parameters) != 0 {
+			p.checkParameterOrder(parameters[len(parameters)-1], param)
+		}
+		if param.Name.Token.Type == lexer.SELF && len(parameters) != 0 {
+			p.pushError(fmt.Sprintf("[%d:%d] self must be the first parameter",
+				param.Name.Token.Position().Line(), param.Name.Token.Position().Column()))
+		}
+		parameters = append(parameters, param)
 
-	ident := ast.NewIdentifier(p.curToken)
-	identifiers = append(identifiers, ident)
-
-	for p.peekTokenIs(lexer.COMMA) {
+		if !p.peekTokenIs(lexer.COMMA) {
+			break
+		}
 		p.nextToken()
 		p.nextToken()
-		ident := ast.NewIdentifier(p.curToken)
-		identifiers = append(identifiers, ident)
 	}
 
 	if !p.expectPeek(lexer.RIGHT_PARENTHESIS) {
 		return nil
 	}
 
-	return identifiers
+	return parameters
+}
+
+// parseFunctionParameter parses a parameter name with its optional type and
+// default value, x, x: int, x = 1 or x: int = 1. A variadic parameter is
+// prefixed with ..., ...rest
+func (p *Parser) parseFunctionParameter() *ast.Parameter {
+	variadic := false
+	if p.curTokenIs(lexer.ELLIPSIS) {
+		variadic = true
+		p.nextToken()
+	}
+
+	param := ast.NewParameter(ast.NewIdentifier(p.curToken))
+	param.Variadic = variadic
+	if !p.curTokenIs(lexer.IDENTIFIER) && !p.curTokenIs(lexer.SELF) {
+		p.pushError(fmt.Sprintf("expected parameter name, got %s instead", p.curToken.Type))
+	}
+
+	if p.peekTokenIs(lexer.COLON) {
+		p.nextToken()
+		p.nextToken()
+		param.Type = p.parseTypeName()
+	}
+
+	if p.peekTokenIs(lexer.ASSIGN) {
+		p.nextToken()
+		p.nextToken()
+		param.Default = p.parseExpression(LOWEST)
+	}
+	return param
+}
+
+// checkParameterOrder reports parameters that could never be bound by a
+// positional argument: anything after a variadic parameter, or a parameter
+// without a default after one with a default.
+func (p *Parser) checkParameterOrder(previous, param *ast.Parameter) {
+	position := param.Name.Token.Position()
+	switch {
+	case previous.Variadic:
+		p.pushError(fmt.Sprintf("[%d:%d] parameter %s follows variadic parameter %s",
+			position.Line(), position.Column(), param.Name.Value, previous.Name.Value))
+	case previous.Default != nil && param.Default == nil && !param.Variadic:
+		p.pushError(fmt.Sprintf("[%d:%d] parameter %s without a default follows parameter %s with a default",
+			position.Line(), position.Column(), param.Name.Value, previous.Name.Value))
+	}
+}
+
+// parseReturnTypes parses the return signature following ->, either a single
+// type or a parenthesized list of types: -> int, -> (int, string)
+func (p *Parser) parseReturnTypes() []*ast.Identifier {
+	types := []*ast.Identifier{}
+
+	p.nextToken()
+	if !p.curTokenIs(lexer.LEFT_PARENTHESIS) {
+		return append(types, p.parseTypeName())
+	}
+
+	for !p.peekTokenIs(lexer.RIGHT_PARENTHESIS) {
+		p.nextToken()
+		types = append(types, p.parseTypeName())
+		if !p.peekTokenIs(lexer.RIGHT_PARENTHESIS) && !p.expectPeek(lexer.COMMA) {
+			return nil
+		}
+	}
+	p.nextToken()
+
+	return types
 }
 
 func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
@@ -455,6 +895,7 @@ func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
 }
 
 func (p *Parser) parseCallParameters() []ast.Expression {
+	defer p.allowStructLiterals(true)()
 	args := []ast.Expression{}
 
 	if p.peekTokenIs(lexer.RIGHT_PARENTHESIS) {
@@ -464,12 +905,22 @@ func (p *Parser) parseCallParameters() []ast.Expression {
 
 	p.nextToken()
 
-	args = append(args, p.parseExpression(LOWEST))
+	named := false
+	for {
+		arg := p.parseCallArgument()
+		if _, ok := arg.(*ast.NamedArgument); ok {
+			named = true
+		} else if named {
+			p.pushError(fmt.Sprintf("[%d:%d] positional argument follows named argument",
+				p.curToken.Position().Line(), p.curToken.Position().Column()))
+		}
+		args = append(args, arg)
 
-	for p.peekTokenIs(lexer.COMMA) {
+		if !p.peekTokenIs(lexer.COMMA) {
+			break
+		}
 		p.nextToken()
 		p.nextToken()
-		args = append(args, p.parseExpression(LOWEST))
 	}
 
 	if !p.expectPeek(lexer.RIGHT_PARENTHESIS) {
@@ -479,6 +930,27 @@ func (p *Parser) parseCallParameters() []ast.Expression {
 	return args
 }
 
+// parseCallArgument parses a call argument: a value, a named argument x: 1
+// or an array spread into several arguments ...xs
+func (p *Parser) parseCallArgument() ast.Expression {
+	if p.curTokenIs(lexer.ELLIPSIS) {
+		spread := ast.NewSpreadExpression(p.curToken)
+		p.nextToken()
+		spread.Value = p.parseExpression(LOWEST)
+		return spread
+	}
+
+	if p.curTokenIs(lexer.IDENTIFIER) && p.peekTokenIs(lexer.COLON) {
+		arg := ast.NewNamedArgument(p.curToken)
+		p.nextToken()
+		p.nextToken()
+		arg.Value = p.parseExpression(LOWEST)
+		return arg
+	}
+
+	return p.parseExpression(LOWEST)
+}
+
 func (p *Parser) parseArrayLiteral() ast.Expression {
 	array := ast.NewArrayLiteral(p.curToken)
 	array.Elements = p.parseExpressionList(lexer.RIGHT_SQUARE_BRACKET)
@@ -486,6 +958,7 @@ func (p *Parser) parseArrayLiteral() ast.Expression {
 }
 
 func (p *Parser) parseExpressionList(end lexer.TokenType) []ast.Expression {
+	defer p.allowStructLiterals(true)()
 	list := []ast.Expression{}
 
 	if p.peekTokenIs(end) {
@@ -511,6 +984,7 @@ func (p *Parser) parseExpressionList(end lexer.TokenType) []ast.Expression {
 
 func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
 	expression := ast.NewIndexExpression(p.curToken, left)
+	defer p.allowStructLiterals(true)()
 
 	p.nextToken()
 	expression.Index = p.parseExpression(LOWEST)
@@ -525,6 +999,7 @@ func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
 func (p *Parser) parseHashLiteral() ast.Expression {
 	hash := ast.NewHashLiteral(p.curToken)
 	hash.Pairs = make(map[ast.Expression]ast.Expression)
+	defer p.allowStructLiterals(true)()
 
 	for !p.peekTokenIs(lexer.RIGHT_CURLY_BRACKET) {
 		p.nextToken()
@@ -551,6 +1026,14 @@ func (p *Parser) parseHashLiteral() ast.Expression {
 	return hash
 }
 
+// parseLoopBody parses the body of a loop, where break and continue are
+// valid.
+func (p *Parser) parseLoopBody() *ast.BlockStatement {
+	p.loops++
+	defer func() { p.loops-- }()
+	return p.parseBlockStatement()
+}
+
 func (p *Parser) parseLoopStatement() ast.Expression {
 	stmt := ast.NewLoopStatement(p.curToken)
 
@@ -559,7 +1042,7 @@ func (p *Parser) parseLoopStatement() ast.Expression {
 	}
 	p.nextToken()
 
-	stmt.Body = p.parseBlockStatement()
+	stmt.Body = p.parseLoopBody()
 
 	return stmt
 }
@@ -568,14 +1051,14 @@ func (p *Parser) parseWhileStatement() ast.Expression {
 	stmt := ast.NewLoopStatement(p.curToken)
 
 	p.nextToken()
-	stmt.WhileCondition = p.parseExpression(LOWEST)
+	stmt.WhileCondition = p.parseClauseExpression()
 
 	if !p.peekTokenIs(lexer.ARROW) && !p.peekTokenIs(lexer.LEFT_CURLY_BRACKET) {
 		return nil
 	}
 	p.nextToken()
 
-	stmt.Body = p.parseBlockStatement()
+	stmt.Body = p.parseLoopBody()
 
 	return stmt
 }
@@ -584,14 +1067,14 @@ func (p *Parser) parseUntilStatement() ast.Expression {
 	stmt := ast.NewLoopStatement(p.curToken)
 
 	p.nextToken()
-	stmt.UntilCondition = p.parseExpression(LOWEST)
+	stmt.UntilCondition = p.parseClauseExpression()
 
 	if !p.peekTokenIs(lexer.ARROW) && !p.peekTokenIs(lexer.LEFT_CURLY_BRACKET) {
 		return nil
 	}
 	p.nextToken()
 
-	stmt.Body = p.parseBlockStatement()
+	stmt.Body = p.parseLoopBody()
 
 	return stmt
 }
@@ -600,7 +1083,20 @@ func (p *Parser) parseForStatement() ast.Expression {
 	stmt := ast.NewForStatement(p.curToken)
 	p.nextToken()
 
+	p.enterScope()
+	defer p.leaveScope()
+
 	stmt.Variable = p.parseIdentifier()
+	p.declare(p.curToken.Literal, false)
+
+	if p.peekTokenIs(lexer.COMMA) {
+		p.nextToken()
+		if !p.expectPeek(lexer.IDENTIFIER) {
+			return nil
+		}
+		stmt.Value = p.parseIdentifier()
+		p.declare(p.curToken.Literal, false)
+	}
 
 	if !p.peekTokenIs(lexer.IN) {
 		return nil
@@ -608,13 +1104,41 @@ func (p *Parser) parseForStatement() ast.Expression {
 	p.nextToken()
 	p.nextToken()
 
-	stmt.Iterable = p.parseExpression(LOWEST)
+	stmt.Iterable = p.parseClauseExpression()
 
 	if !p.peekTokenIs(lexer.ARROW) && !p.peekTokenIs(lexer.LEFT_CURLY_BRACKET) {
 		return nil
 	}
 	p.nextToken()
 
+	stmt.Body = p.parseLoopBody()
+
+	return stmt
+}
+
+func (p *Parser) parseWithStatement() ast.Expression {
+	stmt := ast.NewWithStatement(p.curToken)
+	p.nextToken()
+
+	// the resource stops at as, which would otherwise be read as a cast
+	stmt.Resource = p.parseExpression(CAST)
+
+	if !p.expectPeek(lexer.AS) || !p.expectPeek(lexer.IDENTIFIER) {
+		return nil
+	}
+
+	p.enterScope()
+	defer p.leaveScope()
+
+	stmt.Name = ast.NewIdentifier(p.curToken)
+	p.declare(p.curToken.Literal, false)
+
+	if !p.peekTokenIs(lexer.ARROW) && !p.peekTokenIs(lexer.LEFT_CURLY_BRACKET) {
+		p.peekError(lexer.LEFT_CURLY_BRACKET)
+		return nil
+	}
+	p.nextToken()
+
 	stmt.Body = p.parseBlockStatement()
 
 	return stmt
@@ -624,7 +1148,7 @@ func (p *Parser) parseMatchExpression() ast.Expression {
 	expression := ast.NewMatchExpression(p.curToken)
 
 	p.nextToken()
-	expression.Condition = p.parseExpression(LOWEST)
+	expression.Condition = p.parseClauseExpression()
 
 	if !p.peekTokenIs(lexer.LEFT_CURLY_BRACKET) && !p.peekTokenIs(lexer.ARROW) {
 		return nil
@@ -633,7 +1157,7 @@ func (p *Parser) parseMatchExpression() ast.Expression {
 
 	expression.MatchBlock = p.parseMatchBlockStatement()
 
-	for !p.curTokenIs(lexer.RIGHT_CURLY_BRACKET) {
+	for !p.curTokenIs(lexer.RIGHT_CURLY_BRACKET) && !p.curTokenIs(lexer.EOF) {
 		p.nextToken()
 	}
 
@@ -670,7 +1194,7 @@ func (p *Parser) parseMatchBlockStatement() *ast.MatchBlockStatement {
 		return block
 	}
 
-	for !p.curTokenIs(lexer.RIGHT_CURLY_BRACKET) {
+	for !p.curTokenIs(lexer.RIGHT_CURLY_BRACKET) && !p.curTokenIs(lexer.EOF) {
 		stmt := p.parseCaseExpression()
 		if stmt == nil {
 			p.pushError("expected if expression")
@@ -696,13 +1220,21 @@ func (p *Parser) parseCaseExpression() ast.Expression {
 	}
 	p.nextToken()
 
+	// bindings introduced by the pattern are visible in the guard and the
+	// consequence only
+	p.enterScope()
+	defer p.leaveScope()
+
 	expression := ast.NewCaseExpression(p.curToken)
-	expression.Condition = p.parseExpression(LOWEST)
+	expression.Pattern = p.parsePattern()
+	if expression.Pattern == nil {
+		return nil
+	}
 
 	if p.peekTokenIs(lexer.IF) {
 		p.nextToken()
 		p.nextToken()
-		expression.Guard = p.parseExpression(LOWEST)
+		expression.Guard = p.parseClauseExpression()
 	}
 
 	if !p.peekTokenIs(lexer.LEFT_CURLY_BRACKET) && !p.peekTokenIs(lexer.ARROW) {
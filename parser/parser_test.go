@@ -40,6 +40,74 @@ func TestParseLetStatement(t *testing.T) {
 	}
 }
 
+func TestParseLetMutStatement(t *testing.T) {
+	input := `let mut x: int = 5;`
+
+	p := newParser(input)
+	program := p.Parse()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain 1 statements. got=%d", len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.LetStatement)
+	if !ok {
+		t.Fatalf("stmt is not *ast.LetStatement. got=%T", program.Statements[0])
+	}
+
+	if !stmt.Mutable {
+		t.Fatalf("stmt.Mutable is not true")
+	}
+
+	if stmt.Name.Value != "x" {
+		t.Fatalf("stmt.Name.Value not 'x'. got=%s", stmt.Name.Value)
+	}
+
+	if stmt.Type.Value != "int" {
+		t.Fatalf("stmt.Type.Value not 'int'. got=%s", stmt.Type.Value)
+	}
+}
+
+func TestParseDestructuringLetStatement(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		names    []string
+	}{
+		{input: `let a, b = f()`, expected: "let a, b = f(();", names: []string{"a", "b"}},
+		{input: `let mut x, _, z = 1, 2, 3`, expected: "let mut x, _, z = 1, 2, 3;", names: []string{"x", "_", "z"}},
+	}
+
+	for _, tt := range tests {
+		p := newParser(tt.input)
+		program := p.Parse()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statements. got=%d", len(program.Statements))
+		}
+
+		stmt, ok := program.Statements[0].(*ast.LetStatement)
+		if !ok {
+			t.Fatalf("stmt is not *ast.LetStatement. got=%T", program.Statements[0])
+		}
+
+		if stmt.String() != tt.expected {
+			t.Fatalf("statement not %q. got=%q", tt.expected, stmt.String())
+		}
+
+		if len(stmt.Names) != len(tt.names) {
+			t.Fatalf("stmt.Names does not contain %d names. got=%d", len(tt.names), len(stmt.Names))
+		}
+		for i, name := range stmt.Names {
+			if name.Value != tt.names[i] {
+				t.Fatalf("stmt.Names[%d] not %q. got=%q", i, tt.names[i], name.Value)
+			}
+		}
+	}
+}
+
 func TestParseReturnStatement(t *testing.T) {
 	input := `return 5;`
 
@@ -266,6 +334,111 @@ func TestParseFunctionLiteral(t *testing.T) {
 	}
 }
 
+func TestParseFunctionSignature(t *testing.T) {
+	tests := []struct {
+		input       string
+		parameters  []string
+		returnTypes []string
+	}{
+		{input: `fn(x : int, y) { x }`, parameters: []string{"x: int", "y"}},
+		{input: `fn foobar(x: int, y: uint8) -> (int) { x }`, parameters: []string{"x: int", "y: uint8"}, returnTypes: []string{"int"}},
+		{input: `fn foobar(x: int) -> (int, string) { x }`, parameters: []string{"x: int"}, returnTypes: []string{"int", "string"}},
+		{input: `fn foobar -> bool => true`, returnTypes: []string{"bool"}},
+		{input: `fn(a, b: int = 2, ...rest) { a }`, parameters: []string{"a", "b: int = 2", "...rest"}},
+		{input: `fn main(args: [string]) -> [[int]] { a }`, parameters: []string{"args: [string]"}, returnTypes: []string{"[[int]]"}},
+	}
+
+	for _, tt := range tests {
+		p := newParser(tt.input)
+		program := p.Parse()
+		checkParserErrors(t, p)
+
+		stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+		if !ok {
+			t.Fatalf("stmt is not *ast.ExpressionStatement. got=%T", program.Statements[0])
+		}
+
+		fn, ok := stmt.Expression.(*ast.FunctionLiteral)
+		if !ok {
+			t.Fatalf("stmt.Expression is not *ast.FunctionLiteral. got=%T", stmt.Expression)
+		}
+
+		if len(fn.Parameters) != len(tt.parameters) {
+			t.Fatalf("fn.Parameters does not contain %d parameters. got=%d", len(tt.parameters), len(fn.Parameters))
+		}
+		for i, param := range fn.Parameters {
+			if param.String() != tt.parameters[i] {
+				t.Fatalf("fn.Parameters[%d] not %q. got=%q", i, tt.parameters[i], param.String())
+			}
+		}
+
+		if len(fn.ReturnTypes) != len(tt.returnTypes) {
+			t.Fatalf("fn.ReturnTypes does not contain %d types. got=%d", len(tt.returnTypes), len(fn.ReturnTypes))
+		}
+		for i, typ := range fn.ReturnTypes {
+			if typ.String() != tt.returnTypes[i] {
+				t.Fatalf("fn.ReturnTypes[%d] not %q. got=%q", i, tt.returnTypes[i], typ.String())
+			}
+		}
+	}
+}
+
+func TestParseCallArguments(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{input: `f(1, ...xs)`, expected: []string{"1", "...xs"}},
+		{input: `f(1, b: 2, c: x + 1)`, expected: []string{"1", "b: 2", "c: (x + 1)"}},
+	}
+
+	for _, tt := range tests {
+		p := newParser(tt.input)
+		program := p.Parse()
+		checkParserErrors(t, p)
+
+		stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+		if !ok {
+			t.Fatalf("stmt is not *ast.ExpressionStatement. got=%T", program.Statements[0])
+		}
+
+		call, ok := stmt.Expression.(*ast.CallExpression)
+		if !ok {
+			t.Fatalf("stmt.Expression is not *ast.CallExpression. got=%T", stmt.Expression)
+		}
+
+		if len(call.Parameters) != len(tt.expected) {
+			t.Fatalf("call.Parameters does not contain %d arguments. got=%d", len(tt.expected), len(call.Parameters))
+		}
+		for i, arg := range call.Parameters {
+			if arg.String() != tt.expected[i] {
+				t.Fatalf("call.Parameters[%d] not %q. got=%q", i, tt.expected[i], arg.String())
+			}
+		}
+	}
+}
+
+func TestParseParameterErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: `fn(...rest, a) { a }`, expected: "[1:13] parameter a follows variadic parameter rest"},
+		{input: `fn(a = 1, b) { a }`, expected: "[1:11] parameter b without a default follows parameter a with a default"},
+		{input: `f(a: 1, 2)`, expected: "[1:9] positional argument follows named argument"},
+	}
+
+	for _, tt := range tests {
+		p := newParser(tt.input)
+		p.Parse()
+
+		errors := p.Errors()
+		if len(errors) == 0 || errors[0] != tt.expected {
+			t.Fatalf("expected error %q. got=%q", tt.expected, errors)
+		}
+	}
+}
+
 func TestParseCallExpression(t *testing.T) {
 	input := `add(1, 2 * 3, 4 + 5);`
 
@@ -308,6 +481,502 @@ func TestParseCallExpression(t *testing.T) {
 	}
 }
 
+func TestParseAssignExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: `x = 5`, expected: "(x = 5)"},
+		{input: `x += 1 + 2`, expected: "(x += (1 + 2))"},
+		{input: `x <<= 2`, expected: "(x <<= 2)"},
+		{input: `a = b = 1`, expected: "(a = (b = 1))"},
+		{input: `a[i] = v`, expected: "((a[i]) = v)"},
+		{input: `h["k"] -= 1`, expected: "((h[\"k\"]) -= 1)"},
+		{input: `x++`, expected: "(x++)"},
+		{input: `x--`, expected: "(x--)"},
+	}
+
+	for _, tt := range tests {
+		p := newParser(tt.input)
+		program := p.Parse()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statements. got=%d", len(program.Statements))
+		}
+
+		stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+		if !ok {
+			t.Fatalf("stmt is not *ast.ExpressionStatement. got=%T", program.Statements[0])
+		}
+
+		if _, ok := stmt.Expression.(*ast.AssignExpression); !ok {
+			t.Fatalf("stmt.Expression is not *ast.AssignExpression. got=%T", stmt.Expression)
+		}
+
+		if stmt.Expression.String() != tt.expected {
+			t.Fatalf("stmt.Expression not %q. got=%s", tt.expected, stmt.Expression.String())
+		}
+	}
+
+	p := newParser(`1 = 2`)
+	p.Parse()
+	if len(p.Errors()) == 0 {
+		t.Fatalf("expected an error assigning to a literal")
+	}
+}
+
+func TestParseImmutableAssignment(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{input: "let mut x = 1\nx = 2"},
+		{input: "let x = 1\nx = 2", expected: []string{"[2:1] cannot assign to immutable variable: x"}},
+		{input: "let x = 1\nx += 2", expected: []string{"[2:1] cannot assign to immutable variable: x"}},
+		{input: "let a = [1]\na[0]++", expected: []string{"[2:1] cannot assign to immutable variable: a"}},
+		{input: "let x = 1\nfn f { let mut x = 1; x = 2 }"},
+		{input: "let mut x = 1\nfn f { let x = 1; x = 2 }", expected: []string{"[2:19] cannot assign to immutable variable: x"}},
+		{input: "fn f(n) { n = 1 }", expected: []string{"[1:11] cannot assign to immutable variable: n"}},
+		{input: "for i in xs { i = 1 }", expected: []string{"[1:15] cannot assign to immutable variable: i"}},
+		{input: "fn f { y = 1 }\nlet mut y = 0"},
+		{input: "let x = 1; if true { let mut x = 2 }; x = 3"},
+		{input: "let mut x = 1; if true { let x = 2 }; x = 3", expected: []string{"[1:39] cannot assign to immutable variable: x"}},
+		{input: "let mut x = 1; while x < 3 { let x = 9 }; x = 3", expected: []string{"[1:43] cannot assign to immutable variable: x"}},
+		{input: "let mut x = 1; match x { case n { let x = n } }; x = 2"},
+	}
+
+	for _, tt := range tests {
+		p := newParser(tt.input)
+		p.Parse()
+
+		errors := p.Errors()
+		if len(errors) != len(tt.expected) {
+			t.Fatalf("%q: expected %d errors, got=%q", tt.input, len(tt.expected), errors)
+		}
+		for i, msg := range tt.expected {
+			if errors[i] != msg {
+				t.Fatalf("%q: error not %q. got=%q", tt.input, msg, errors[i])
+			}
+		}
+	}
+}
+
+func TestParsePowerExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: `2 ** 3`, expected: "(2 ** 3)"},
+		{input: `2 ** 3 ** 2`, expected: "(2 ** (3 ** 2))"},
+		{input: `-2 ** 2`, expected: "(-(2 ** 2))"},
+		{input: `2 * 3 ** 2`, expected: "(2 * (3 ** 2))"},
+	}
+
+	for _, tt := range tests {
+		p := newParser(tt.input)
+		program := p.Parse()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statements. got=%d", len(program.Statements))
+		}
+
+		if program.Statements[0].String() != tt.expected {
+			t.Fatalf("statement not %q. got=%s", tt.expected, program.Statements[0].String())
+		}
+	}
+}
+
+func TestParseLogicalExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: `a && b || c`, expected: "((a && b) || c)"},
+		{input: `a || b && c`, expected: "(a || (b && c))"},
+		{input: `x != null && x > 0`, expected: "((x != null) && (x > 0))"},
+		{input: `a or b and c`, expected: "(a or (b and c))"},
+		{input: `!a && b`, expected: "((!a) && b)"},
+	}
+
+	for _, tt := range tests {
+		p := newParser(tt.input)
+		program := p.Parse()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statements. got=%d", len(program.Statements))
+		}
+
+		if program.Statements[0].String() != tt.expected {
+			t.Fatalf("statement not %q. got=%s", tt.expected, program.Statements[0].String())
+		}
+	}
+}
+
+func TestParseTryExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: `f(x)?`, expected: "f(()?"},
+		{input: `f(x)? + 1`, expected: "(f(()? + 1)"},
+		{input: `-a?`, expected: "(-a?)"},
+		{input: `h[k]?`, expected: "(h[k])?"},
+		{input: `x == none`, expected: "(x == none)"},
+	}
+
+	for _, tt := range tests {
+		p := newParser(tt.input)
+		program := p.Parse()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statements. got=%d", len(program.Statements))
+		}
+
+		if program.Statements[0].String() != tt.expected {
+			t.Fatalf("statement not %q. got=%s", tt.expected, program.Statements[0].String())
+		}
+	}
+}
+
+func TestParseDeferStatement(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: `defer close(f)`, expected: "defer close(();"},
+		{input: `defer fn(r) { r }`, expected: "defer fn(...) => r;"},
+	}
+
+	for _, tt := range tests {
+		p := newParser(tt.input)
+		program := p.Parse()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statements. got=%d", len(program.Statements))
+		}
+
+		stmt, ok := program.Statements[0].(*ast.DeferStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.DeferStatement. got=%T", program.Statements[0])
+		}
+
+		if stmt.String() != tt.expected {
+			t.Fatalf("statement not %q. got=%s", tt.expected, stmt.String())
+		}
+	}
+}
+func TestParseWithStatement(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: `with open(p)? as fp => read(fp)`, expected: "with open(()? as fp => read(()"},
+		{input: `with x + 1 as y { y }`, expected: "with (x + 1) as y => y"},
+	}
+
+	for _, tt := range tests {
+		p := newParser(tt.input)
+		program := p.Parse()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statements. got=%d", len(program.Statements))
+		}
+
+		if program.Statements[0].String() != tt.expected {
+			t.Fatalf("statement not %q. got=%s", tt.expected, program.Statements[0].String())
+		}
+	}
+
+	p := newParser(`with fp as x { x = 1 }`)
+	p.Parse()
+	if len(p.Errors()) == 0 {
+		t.Fatalf("expected an error assigning the with variable")
+	}
+}
+
+func TestParseStructs(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: "struct File {\n fd: int\n name: string\n}", expected: "struct File { fd: int, name: string }"},
+		{input: "struct Empty {}", expected: "struct Empty {  }"},
+		{input: "File{fd: 3, name: n}", expected: "File{fd: 3, name: n}"},
+		{input: "f.fd + 1", expected: "((f.fd) + 1)"},
+		{input: "a.b.c[0]", expected: "(((a.b).c)[0])"},
+		{input: "f.fd = 4", expected: "((f.fd) = 4)"},
+		{input: "if f.ok { 1 }", expected: "if (f.ok) => 1"},
+		{input: "if p == (Point{x: 1}) { 1 }", expected: "if (p == Point{x: 1}) => 1"},
+		{input: "for x in xs { x }", expected: "for => x"},
+		{input: "union Shape {\n Circle(r: float)\n Rect(w: float, h: float)\n Empty\n}", expected: "union Shape { Circle(r: float), Rect(w: float, h: float), Empty }"},
+		{input: "union Unit { A, B() }", expected: "union Unit { A, B() }"},
+		{input: "File => {\n fn read(self, n: int) => n\n fn close(self) { self.fd = -1 }\n}", expected: "File => { read, close }"},
+		{input: "f.read(10)", expected: "(f.read)(()"},
+		{input: "type Port = uint16", expected: "type Port = uint16"},
+		{input: "type UserID int", expected: "type UserID int"},
+		{input: "type IDs [UserID]", expected: "type IDs [UserID]"},
+		{input: "type(x)", expected: "type(()"},
+	}
+
+	for _, tt := range tests {
+		p := newParser(tt.input)
+		program := p.Parse()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statements. got=%d", len(program.Statements))
+		}
+
+		if program.Statements[0].String() != tt.expected {
+			t.Fatalf("statement not %q. got=%s", tt.expected, program.Statements[0].String())
+		}
+	}
+
+	errors := []string{
+		"struct P { x: int, x: int }",
+		"P{x: 1, x: 2}",
+		"let p = P{x: 1}; p.x = 2",
+		"union U { A, A }",
+		"union U { A(x: int, x: int) }",
+		"T => { fn f() => 1 }",
+		"T => { fn f(x, self) => 1 }",
+		"T => { let x = 1 }",
+		"fn f(x) { x.y = 1 }",
+		"type U = ",
+		"type U 5",
+	}
+	for _, input := range errors {
+		p := newParser(input)
+		p.Parse()
+		if len(p.Errors()) == 0 {
+			t.Fatalf("expected an error parsing %q", input)
+		}
+	}
+}
+
+func TestParseBuiltinTypeNames(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: "struct INTEGER { x: int }\nINTEGER{x: 1} + 1", expected: "[1:8] cannot declare struct INTEGER: INTEGER is a builtin type"},
+		{input: "struct HASH { x: int }\nHASH{x: 1}[\"a\"]", expected: "[1:8] cannot declare struct HASH: HASH is a builtin type"},
+		{input: "struct BOOLEAN {x: int}\nBOOLEAN{x:1} == true", expected: "[1:8] cannot declare struct BOOLEAN: BOOLEAN is a builtin type"},
+		{input: "struct ERROR { x: int }\nlet e = ERROR{x: 1}", expected: "[1:8] cannot declare struct ERROR: ERROR is a builtin type"},
+		{input: "union FLOAT { A(x: int) }\nA(1) + 1.0", expected: "[1:7] cannot declare union FLOAT: FLOAT is a builtin type"},
+		{input: "type INTEGER int\n(5 as INTEGER) + 1", expected: "[1:6] cannot declare type INTEGER: INTEGER is a builtin type"},
+		{input: "type STRING int\n(5 as STRING) + \"x\"", expected: "[1:6] cannot declare type STRING: STRING is a builtin type"},
+	}
+
+	for _, tt := range tests {
+		p := newParser(tt.input)
+		p.Parse()
+		if errors := p.Errors(); len(errors) != 1 || errors[0] != tt.expected {
+			t.Errorf("wrong errors for %q. got=%q, want=%q", tt.input, errors, tt.expected)
+		}
+	}
+}
+
+func TestParseCastExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: `x as uint8`, expected: "(x as uint8)"},
+		{input: `x + 1 as float`, expected: "((x + 1) as float)"},
+		{input: `'a' as int`, expected: "('a' as int)"},
+	}
+
+	for _, tt := range tests {
+		p := newParser(tt.input)
+		program := p.Parse()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statements. got=%d", len(program.Statements))
+		}
+
+		stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+		if !ok {
+			t.Fatalf("stmt is not *ast.ExpressionStatement. got=%T", program.Statements[0])
+		}
+
+		if _, ok := stmt.Expression.(*ast.CastExpression); !ok {
+			t.Fatalf("stmt.Expression is not *ast.CastExpression. got=%T", stmt.Expression)
+		}
+
+		if stmt.Expression.String() != tt.expected {
+			t.Fatalf("stmt.Expression not %q. got=%s", tt.expected, stmt.Expression.String())
+		}
+	}
+}
+
+func TestParseForStatement(t *testing.T) {
+	tests := []struct {
+		input    string
+		variable string
+		value    string
+		iterable string
+	}{
+		{input: `for x in xs { x }`, variable: "x", iterable: "xs"},
+		{input: `for k, v in h => k`, variable: "k", value: "v", iterable: "h"},
+	}
+
+	for _, tt := range tests {
+		p := newParser(tt.input)
+		program := p.Parse()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statements. got=%d", len(program.Statements))
+		}
+
+		stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+		if !ok {
+			t.Fatalf("stmt is not *ast.ExpressionStatement. got=%T", program.Statements[0])
+		}
+
+		loop, ok := stmt.Expression.(*ast.ForStatement)
+		if !ok {
+			t.Fatalf("stmt.Expression is not *ast.ForStatement. got=%T", stmt.Expression)
+		}
+
+		if loop.Variable.String() != tt.variable {
+			t.Fatalf("loop.Variable not %q. got=%s", tt.variable, loop.Variable.String())
+		}
+
+		if tt.value == "" && loop.Value != nil {
+			t.Fatalf("loop.Value not nil. got=%s", loop.Value.String())
+		}
+		if tt.value != "" && (loop.Value == nil || loop.Value.String() != tt.value) {
+			t.Fatalf("loop.Value not %q. got=%v", tt.value, loop.Value)
+		}
+
+		if loop.Iterable.String() != tt.iterable {
+			t.Fatalf("loop.Iterable not %q. got=%s", tt.iterable, loop.Iterable.String())
+		}
+
+		if len(loop.Body.Statements) != 1 {
+			t.Fatalf("loop.Body.Statements does not contain 1 statement. got=%d", len(loop.Body.Statements))
+		}
+	}
+}
+
+func TestParseMatchExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		patterns []string
+		guards   []string
+	}{
+		{input: `match x { case 1 => a case _ => b }`, patterns: []string{"1", "_"}, guards: []string{"", ""}},
+		{input: `match x { case n if n > 3 => n }`, patterns: []string{"n"}, guards: []string{"(n > 3)"}},
+		{input: `match x { case 1 | -2 | 3..=5 => a }`, patterns: []string{"1 | (-2) | 3..=5"}, guards: []string{""}},
+		{input: `match x { case 'a'..'z' => a }`, patterns: []string{"'a'..'z'"}, guards: []string{""}},
+		{input: `match x { case [a, _, ...rest] => a }`, patterns: []string{"[a, _, ...rest]"}, guards: []string{""}},
+		{input: `match x { case {"k": [v], 1: 2} => v }`, patterns: []string{`{"k": [v], 1: 2}`}, guards: []string{""}},
+		{input: `match x { case ok(v) => v case err(_) | none => 0 }`, patterns: []string{"ok(v)", "err(_) | none"}, guards: []string{"", ""}},
+		{input: `match x { case some([a, b]) if a > b => a }`, patterns: []string{"some([a, b])"}, guards: []string{"(a > b)"}},
+	}
+
+	for _, tt := range tests {
+		p := newParser(tt.input)
+		program := p.Parse()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statements. got=%d", len(program.Statements))
+		}
+
+		stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+		if !ok {
+			t.Fatalf("stmt is not *ast.ExpressionStatement. got=%T", program.Statements[0])
+		}
+
+		match, ok := stmt.Expression.(*ast.MatchExpression)
+		if !ok {
+			t.Fatalf("stmt.Expression is not *ast.MatchExpression. got=%T", stmt.Expression)
+		}
+
+		if len(match.MatchBlock.Cases) != len(tt.patterns) {
+			t.Fatalf("match has %d cases, want %d", len(match.MatchBlock.Cases), len(tt.patterns))
+		}
+
+		for i, c := range match.MatchBlock.Cases {
+			if c.Pattern.String() != tt.patterns[i] {
+				t.Errorf("case %d pattern not %q. got=%q", i, tt.patterns[i], c.Pattern.String())
+			}
+			guard := ""
+			if c.Guard != nil {
+				guard = c.Guard.String()
+			}
+			if guard != tt.guards[i] {
+				t.Errorf("case %d guard not %q. got=%q", i, tt.guards[i], guard)
+			}
+		}
+	}
+}
+
+func TestParseLabeledLoop(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: "outer: loop { break outer }", expected: "outer: loop => break outer"},
+		{input: "outer: for x in xs { continue outer }", expected: "outer: for => continue outer"},
+		{input: "outer: while x { break outer x }", expected: "outer: while => break outer x"},
+		{input: "loop { break x }", expected: "loop => break x"},
+		{input: "loop { break\nx }", expected: "loop { breakx }"},
+		{input: "let x = l: loop { break l 1 }", expected: "let x = l: loop => break l 1;"},
+	}
+
+	for _, tt := range tests {
+		p := newParser(tt.input)
+		program := p.Parse()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statements. got=%d", len(program.Statements))
+		}
+
+		if program.Statements[0].String() != tt.expected {
+			t.Fatalf("statement not %q. got=%q", tt.expected, program.Statements[0].String())
+		}
+	}
+}
+
+func TestParseUnknownLabel(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: "loop { continue outer }", expected: "[1:17] unknown loop label: outer"},
+		{input: "outer: if x { }", expected: "[1:8] expected a loop after label outer, got IF"},
+		{input: "break", expected: "[1:1] break outside a loop"},
+		{input: "if x { continue }", expected: "[1:8] continue outside a loop"},
+		{input: "loop { fn g { break } }", expected: "[1:15] break outside a loop"},
+		{input: "outer: loop { fn g { break outer } }", expected: "[1:22] break outside a loop"},
+		{input: "outer: loop { fn g { loop { continue outer } } }", expected: "[1:38] unknown loop label: outer"},
+		{input: "if P{x: 1} == P{x: 1} { 1 }", expected: "[1:5] struct literal in condition must be parenthesized"},
+		{input: "for p in P{xs: [1]} { p }", expected: "[1:11] struct literal in condition must be parenthesized"},
+	}
+
+	for _, tt := range tests {
+		p := newParser(tt.input)
+		p.Parse()
+
+		errors := p.Errors()
+		if len(errors) == 0 || errors[0] != tt.expected {
+			t.Fatalf("expected error %q. got=%q", tt.expected, errors)
+		}
+	}
+}
+
 func checkParserErrors(t *testing.T, p *parser.Parser) {
 	errors := p.Errors()
 	if len(errors) == 0 {
@@ -0,0 +1,206 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/poolpOrg/julu/ast"
+	"github.com/poolpOrg/julu/lexer"
+)
+
+// parsePattern parses the pattern of a match case, alternatives included:
+//
+//	case 1 | 2 | 3..=5
+func (p *Parser) parsePattern() ast.Pattern {
+	token := p.curToken
+	first := p.parsePrimaryPattern()
+	if first == nil || !p.peekTokenIs(lexer.BITWISE_OR) {
+		return first
+	}
+
+	pattern := ast.NewAlternativePattern(token)
+	pattern.Alternatives = append(pattern.Alternatives, first)
+	for p.peekTokenIs(lexer.BITWISE_OR) {
+		p.nextToken()
+		p.nextToken()
+		alternative := p.parsePrimaryPattern()
+		if alternative == nil {
+			return nil
+		}
+		pattern.Alternatives = append(pattern.Alternatives, alternative)
+	}
+	return pattern
+}
+
+func (p *Parser) parsePrimaryPattern() ast.Pattern {
+	switch p.curToken.Type {
+	case lexer.IDENTIFIER:
+		if p.curToken.Literal == "_" {
+			return ast.NewWildcardPattern(p.curToken)
+		}
+		if p.peekTokenIs(lexer.LEFT_PARENTHESIS) {
+			return p.parseVariantPattern()
+		}
+		p.declare(p.curToken.Literal, false)
+		return ast.NewBindingPattern(p.curToken)
+
+	case lexer.LEFT_SQUARE_BRACKET:
+		return p.parseArrayPattern()
+
+	case lexer.LEFT_CURLY_BRACKET:
+		return p.parseHashPattern()
+	}
+
+	token := p.curToken
+	value := p.parseLiteralValue()
+	if value == nil {
+		return nil
+	}
+	if !p.peekTokenIs(lexer.DOTDOT) && !p.peekTokenIs(lexer.DOTDOT_EQUAL) {
+		return ast.NewLiteralPattern(token, value)
+	}
+
+	p.nextToken()
+	pattern := ast.NewRangePattern(p.curToken, value)
+	p.nextToken()
+	pattern.High = p.parseLiteralValue()
+	if pattern.High == nil {
+		return nil
+	}
+	return pattern
+}
+
+// parseLiteralValue parses the literals allowed in patterns. Unlike in
+// expressions, a colon after a number is not a cast so that it can be used
+// as a hash pattern key.
+func (p *Parser) parseLiteralValue() ast.Expression {
+	switch p.curToken.Type {
+	case lexer.INTEGER:
+		if expr := p.newIntegerLiteral(); expr != nil {
+			return expr
+		}
+		return nil
+	case lexer.FLOAT:
+		if expr := p.newFloatLiteral(); expr != nil {
+			return expr
+		}
+		return nil
+	case lexer.STRING:
+		return p.parseStringLiteral()
+	case lexer.RUNE:
+		return p.parseCharLiteral()
+	case lexer.TRUE, lexer.FALSE:
+		return p.parseBoolean()
+	case lexer.NULL:
+		return p.parseNull()
+	case lexer.NONE:
+		return p.parseNone()
+	case lexer.SUB:
+		if !p.peekTokenIs(lexer.INTEGER) && !p.peekTokenIs(lexer.FLOAT) {
+			break
+		}
+		expression := ast.NewPrefixExpression(p.curToken, p.curToken.Literal)
+		p.nextToken()
+		expression.Right = p.parseLiteralValue()
+		if expression.Right == nil {
+			return nil
+		}
+		return expression
+	}
+
+	p.pushError(fmt.Sprintf("[%d:%d] unexpected %s in pattern",
+		p.curToken.Position().Line(), p.curToken.Position().Column(), p.curToken.Type))
+	return nil
+}
+
+// parseVariantPattern parses a variant constructor and the patterns of its
+// arguments:
+//
+//	case ok(v)
+func (p *Parser) parseVariantPattern() ast.Pattern {
+	pattern := ast.NewVariantPattern(p.curToken)
+	p.nextToken()
+
+	for !p.peekTokenIs(lexer.RIGHT_PARENTHESIS) {
+		p.nextToken()
+		argument := p.parsePattern()
+		if argument == nil {
+			return nil
+		}
+		pattern.Arguments = append(pattern.Arguments, argument)
+
+		if !p.peekTokenIs(lexer.RIGHT_PARENTHESIS) && !p.expectPeek(lexer.COMMA) {
+			return nil
+		}
+	}
+
+	if !p.expectPeek(lexer.RIGHT_PARENTHESIS) {
+		return nil
+	}
+	return pattern
+}
+
+func (p *Parser) parseArrayPattern() ast.Pattern {
+	pattern := ast.NewArrayPattern(p.curToken)
+
+	for !p.peekTokenIs(lexer.RIGHT_SQUARE_BRACKET) {
+		p.nextToken()
+		if p.curTokenIs(lexer.ELLIPSIS) {
+			p.nextToken()
+			if !p.curTokenIs(lexer.IDENTIFIER) {
+				p.pushError(fmt.Sprintf("[%d:%d] expected a name after ...",
+					p.curToken.Position().Line(), p.curToken.Position().Column()))
+				return nil
+			}
+			pattern.Rest = p.parsePrimaryPattern()
+			break
+		}
+
+		element := p.parsePattern()
+		if element == nil {
+			return nil
+		}
+		pattern.Elements = append(pattern.Elements, element)
+
+		if !p.peekTokenIs(lexer.RIGHT_SQUARE_BRACKET) && !p.expectPeek(lexer.COMMA) {
+			return nil
+		}
+	}
+
+	if !p.expectPeek(lexer.RIGHT_SQUARE_BRACKET) {
+		return nil
+	}
+	return pattern
+}
+
+func (p *Parser) parseHashPattern() ast.Pattern {
+	pattern := ast.NewHashPattern(p.curToken)
+
+	for !p.peekTokenIs(lexer.RIGHT_CURLY_BRACKET) {
+		p.nextToken()
+		key := p.parseLiteralValue()
+		if key == nil {
+			return nil
+		}
+
+		if !p.expectPeek(lexer.COLON) {
+			return nil
+		}
+
+		p.nextToken()
+		value := p.parsePattern()
+		if value == nil {
+			return nil
+		}
+		pattern.Keys = append(pattern.Keys, key)
+		pattern.Values = append(pattern.Values, value)
+
+		if !p.peekTokenIs(lexer.RIGHT_CURLY_BRACKET) && !p.expectPeek(lexer.COMMA) {
+			return nil
+		}
+	}
+
+	if !p.expectPeek(lexer.RIGHT_CURLY_BRACKET) {
+		return nil
+	}
+	return pattern
+}
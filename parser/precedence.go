@@ -7,21 +7,37 @@ import (
 const (
 	_ int = iota
 	LOWEST
+	ASSIGN // =, +=, ...
 	CAST
+	LOGICAL_OR  // ||
+	LOGICAL_AND // &&
 	EQUALS      // ==
 	LESSGREATER // > or <
-	COMPARISON  // &&, ||
 	SUM         // +
 	PRODUCT     // *
 	BITWISE     // &, |, ^
 	BITSHIFT    // <<, >>
 	PREFIX      // -X or !X
+	POWER       // **
 	CALL        // myFunction(X)
 	INDEX       // array[index]
 )
 
 var precedences = map[lexer.TokenType]int{
+	lexer.ASSIGN:             ASSIGN,
+	lexer.ADD_AND_ASSIGN:     ASSIGN,
+	lexer.SUB_AND_ASSIGN:     ASSIGN,
+	lexer.MUL_AND_ASSIGN:     ASSIGN,
+	lexer.DIV_AND_ASSIGN:     ASSIGN,
+	lexer.MOD_AND_ASSIGN:     ASSIGN,
+	lexer.BITWISE_AND_ASSIGN: ASSIGN,
+	lexer.BITWISE_OR_ASSIGN:  ASSIGN,
+	lexer.BITWISE_XOR_ASSIGN: ASSIGN,
+	lexer.LSHIFT_ASSIGN:      ASSIGN,
+	lexer.RSHIFT_ASSIGN:      ASSIGN,
+
 	lexer.COLON:            CAST,
+	lexer.AS:               CAST,
 	lexer.EQUALS:           EQUALS,
 	lexer.NOT_EQUALS:       EQUALS,
 	lexer.LESSER_THAN:      LESSGREATER,
@@ -29,8 +45,8 @@ var precedences = map[lexer.TokenType]int{
 	lexer.LESSER_OR_EQUAL:  LESSGREATER,
 	lexer.GREATER_OR_EQUAL: LESSGREATER,
 
-	lexer.LOGICAL_AND: COMPARISON,
-	lexer.LOGICAL_OR:  COMPARISON,
+	lexer.LOGICAL_AND: LOGICAL_AND,
+	lexer.LOGICAL_OR:  LOGICAL_OR,
 
 	lexer.BITWISE_AND:         BITWISE,
 	lexer.BITWISE_OR:          BITWISE,
@@ -44,8 +60,13 @@ var precedences = map[lexer.TokenType]int{
 	lexer.MUL:                 PRODUCT,
 	lexer.DIV:                 PRODUCT,
 	lexer.MOD:                 PRODUCT,
+	lexer.POW:                 POWER,
 	lexer.LEFT_PARENTHESIS:    CALL,
+	lexer.INCR:                CALL,
+	lexer.DECR:                CALL,
+	lexer.QUESTION:            CALL,
 	lexer.LEFT_SQUARE_BRACKET: INDEX,
+	lexer.DOT:                 INDEX,
 }
 
 func GetPrecedenceTable() map[lexer.TokenType]int {
@@ -33,14 +33,20 @@ func TestPrecedence(t *testing.T) {
 		tokenType lexer.TokenType
 		expected  int
 	}{
+		{tokenType: lexer.ASSIGN, expected: parser.ASSIGN},
+		{tokenType: lexer.ADD_AND_ASSIGN, expected: parser.ASSIGN},
+		{tokenType: lexer.RSHIFT_ASSIGN, expected: parser.ASSIGN},
+		{tokenType: lexer.INCR, expected: parser.CALL},
+		{tokenType: lexer.QUESTION, expected: parser.CALL},
+		{tokenType: lexer.AS, expected: parser.CAST},
 		{tokenType: lexer.EQUALS, expected: parser.EQUALS},
 		{tokenType: lexer.NOT_EQUALS, expected: parser.EQUALS},
 		{tokenType: lexer.LESSER_THAN, expected: parser.LESSGREATER},
 		{tokenType: lexer.GREATER_THAN, expected: parser.LESSGREATER},
 		{tokenType: lexer.LESSER_OR_EQUAL, expected: parser.LESSGREATER},
 		{tokenType: lexer.GREATER_OR_EQUAL, expected: parser.LESSGREATER},
-		{tokenType: lexer.LOGICAL_AND, expected: parser.COMPARISON},
-		{tokenType: lexer.LOGICAL_OR, expected: parser.COMPARISON},
+		{tokenType: lexer.LOGICAL_AND, expected: parser.LOGICAL_AND},
+		{tokenType: lexer.LOGICAL_OR, expected: parser.LOGICAL_OR},
 		{tokenType: lexer.BITWISE_AND, expected: parser.BITWISE},
 		{tokenType: lexer.BITWISE_OR, expected: parser.BITWISE},
 		{tokenType: lexer.BITWISE_XOR, expected: parser.BITWISE},
@@ -53,6 +59,7 @@ func TestPrecedence(t *testing.T) {
 		{tokenType: lexer.MUL, expected: parser.PRODUCT},
 		{tokenType: lexer.DIV, expected: parser.PRODUCT},
 		{tokenType: lexer.MOD, expected: parser.PRODUCT},
+		{tokenType: lexer.POW, expected: parser.POWER},
 		{tokenType: lexer.LEFT_PARENTHESIS, expected: parser.CALL},
 		{tokenType: lexer.IDENTIFIER, expected: parser.LOWEST}, // Example of token not in the map
 	}
@@ -0,0 +1,43 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/poolpOrg/julu/ast"
+)
+
+// The parser keeps track of the bindings declared in each lexical scope so
+// that assignments to immutable bindings are reported before the program
+// runs. Names it does not know about, such as globals declared later in the
+// file, are left to the evaluator to check.
+
+func (p *Parser) enterScope() {
+	p.scopes = append(p.scopes, make(map[string]bool))
+}
+
+func (p *Parser) leaveScope() {
+	p.scopes = p.scopes[:len(p.scopes)-1]
+}
+
+func (p *Parser) declare(name string, mutable bool) {
+	p.scopes[len(p.scopes)-1][name] = mutable
+}
+
+func (p *Parser) isImmutable(name string) bool {
+	for i := len(p.scopes) - 1; i >= 0; i-- {
+		if mutable, ok := p.scopes[i][name]; ok {
+			return !mutable
+		}
+	}
+	return false
+}
+
+// checkMutable reports an error if the variable an assignment target
+// belongs to is known to be immutable.
+func (p *Parser) checkMutable(target ast.Expression) {
+	ident := ast.AssignedIdentifier(target)
+	if ident != nil && p.isImmutable(ident.Value) {
+		p.pushError(fmt.Sprintf("[%d:%d] cannot assign to immutable variable: %s",
+			ident.Token.Position().Line(), ident.Token.Position().Column(), ident.Value))
+	}
+}
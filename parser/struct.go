@@ -0,0 +1,267 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/poolpOrg/julu/ast"
+	"github.com/poolpOrg/julu/lexer"
+	"github.com/poolpOrg/julu/object"
+)
+
+// Like in Go, a struct literal cannot appear unparenthesized in the
+// expression heading a block, such as the condition of an if, since the {
+// opening its fields could also open the block.
+
+// allowStructLiterals allows or forbids struct literals until the returned
+// function restores the previous setting.
+func (p *Parser) allowStructLiterals(allow bool) func() {
+	saved := p.noStructLiterals
+	p.noStructLiterals = !allow
+	return func() { p.noStructLiterals = saved }
+}
+
+// parseClauseExpression parses the expression heading a block.
+func (p *Parser) parseClauseExpression() ast.Expression {
+	defer p.allowStructLiterals(false)()
+	return p.parseExpression(LOWEST)
+}
+
+// parseStructStatement parses a struct declaration, its fields are
+// separated by commas or whitespace:
+//
+//	struct File {
+//	    fd: int
+//	    name: string
+//	}
+func (p *Parser) parseStructStatement() *ast.StructStatement {
+	stmt := ast.NewStructStatement(p.curToken)
+
+	if !p.expectPeek(lexer.IDENTIFIER) {
+		return nil
+	}
+	stmt.Name = ast.NewIdentifier(p.curToken)
+	p.declareType("struct", stmt.Name)
+
+	if !p.expectPeek(lexer.LEFT_CURLY_BRACKET) {
+		return nil
+	}
+
+	stmt.Fields = p.parseFieldDeclarations(lexer.RIGHT_CURLY_BRACKET, "struct "+stmt.Name.Value)
+	if stmt.Fields == nil {
+		return nil
+	}
+
+	return stmt
+}
+
+// declareType declares the type name, a struct, union or named type. It
+// can't take the name of a builtin type since its values are typed after it.
+func (p *Parser) declareType(kind string, name *ast.Identifier) {
+	if object.IsBuiltinType(name.Value) {
+		p.pushError(fmt.Sprintf("[%d:%d] cannot declare %s %s: %s is a builtin type",
+			name.Token.Position().Line(), name.Token.Position().Column(), kind, name.Value, name.Value))
+	}
+	p.declare(name.Value, false)
+}
+
+// parseFieldDeclarations parses typed fields up to the end token, separated
+// by commas or whitespace. It returns nil on error and an empty list if
+// there are no fields.
+func (p *Parser) parseFieldDeclarations(end lexer.TokenType, owner string) []*ast.Parameter {
+	fields := []*ast.Parameter{}
+	seen := make(map[string]bool)
+	for !p.peekTokenIs(end) {
+		if !p.expectPeek(lexer.IDENTIFIER) {
+			return nil
+		}
+		field := ast.NewParameter(ast.NewIdentifier(p.curToken))
+		if seen[field.Name.Value] {
+			p.pushError(fmt.Sprintf("[%d:%d] duplicate field %s in %s",
+				p.curToken.Position().Line(), p.curToken.Position().Column(), field.Name.Value, owner))
+		}
+		seen[field.Name.Value] = true
+
+		if !p.expectPeek(lexer.COLON) {
+			return nil
+		}
+		p.nextToken()
+		if field.Type = p.parseTypeName(); field.Type == nil {
+			return nil
+		}
+		fields = append(fields, field)
+
+		if p.peekTokenIs(lexer.COMMA) {
+			p.nextToken()
+		}
+	}
+	p.nextToken()
+
+	return fields
+}
+
+// parseUnionStatement parses a union declaration, its variants are
+// separated by commas or whitespace and hold typed fields between
+// parentheses:
+//
+//	union Shape {
+//	    Circle(r: float)
+//	    Rect(w: float, h: float)
+//	    Empty
+//	}
+func (p *Parser) parseUnionStatement() *ast.UnionStatement {
+	stmt := ast.NewUnionStatement(p.curToken)
+
+	if !p.expectPeek(lexer.IDENTIFIER) {
+		return nil
+	}
+	stmt.Name = ast.NewIdentifier(p.curToken)
+	p.declareType("union", stmt.Name)
+
+	if !p.expectPeek(lexer.LEFT_CURLY_BRACKET) {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	for !p.peekTokenIs(lexer.RIGHT_CURLY_BRACKET) {
+		if !p.expectPeek(lexer.IDENTIFIER) {
+			return nil
+		}
+		variant := &ast.UnionVariant{Name: ast.NewIdentifier(p.curToken)}
+		if seen[variant.Name.Value] {
+			p.pushError(fmt.Sprintf("[%d:%d] duplicate variant %s in union %s",
+				p.curToken.Position().Line(), p.curToken.Position().Column(), variant.Name.Value, stmt.Name.Value))
+		}
+		seen[variant.Name.Value] = true
+		p.declare(variant.Name.Value, false)
+
+		if p.peekTokenIs(lexer.LEFT_PARENTHESIS) {
+			p.nextToken()
+			variant.Fields = p.parseFieldDeclarations(lexer.RIGHT_PARENTHESIS, "variant "+variant.Name.Value)
+			if variant.Fields == nil {
+				return nil
+			}
+		}
+		stmt.Variants = append(stmt.Variants, variant)
+
+		if p.peekTokenIs(lexer.COMMA) {
+			p.nextToken()
+		}
+	}
+	p.nextToken()
+
+	return stmt
+}
+
+// parseStructLiteral parses a struct literal, Name{field: value, ...}.
+func (p *Parser) parseStructLiteral() ast.Expression {
+	literal := ast.NewStructLiteral(p.curToken)
+	defer p.allowStructLiterals(true)()
+	p.nextToken()
+
+	seen := make(map[string]bool)
+	for !p.peekTokenIs(lexer.RIGHT_CURLY_BRACKET) {
+		if !p.expectPeek(lexer.IDENTIFIER) {
+			return nil
+		}
+		field := &ast.FieldValue{Name: ast.NewIdentifier(p.curToken)}
+		if seen[field.Name.Value] {
+			p.pushError(fmt.Sprintf("[%d:%d] duplicate field %s in %s literal",
+				p.curToken.Position().Line(), p.curToken.Position().Column(), field.Name.Value, literal.Name.Value))
+		}
+		seen[field.Name.Value] = true
+
+		if !p.expectPeek(lexer.COLON) {
+			return nil
+		}
+		p.nextToken()
+		field.Value = p.parseExpression(LOWEST)
+		literal.Fields = append(literal.Fields, field)
+
+		if !p.peekTokenIs(lexer.RIGHT_CURLY_BRACKET) && !p.expectPeek(lexer.COMMA) {
+			return nil
+		}
+	}
+	p.nextToken()
+
+	return literal
+}
+
+func (p *Parser) parseFieldExpression(left ast.Expression) ast.Expression {
+	expression := ast.NewFieldExpression(p.curToken, left)
+
+	if !p.expectPeek(lexer.IDENTIFIER) {
+		return nil
+	}
+	expression.Field = ast.NewIdentifier(p.curToken)
+
+	return expression
+}
+
+// parseTypeStatement parses an alias, type Port = uint16, or a distinct type
+// represented by another one, type UserID int.
+func (p *Parser) parseTypeStatement() *ast.TypeStatement {
+	stmt := ast.NewTypeStatement(p.curToken)
+
+	if !p.expectPeek(lexer.IDENTIFIER) {
+		return nil
+	}
+	stmt.Name = ast.NewIdentifier(p.curToken)
+
+	// values of an alias are typed after its target
+	if p.peekTokenIs(lexer.ASSIGN) {
+		p.nextToken()
+		stmt.Alias = true
+		p.declare(stmt.Name.Value, false)
+	} else {
+		p.declareType("type", stmt.Name)
+	}
+	p.nextToken()
+	stmt.Type = p.parseTypeName()
+	if stmt.Type == nil {
+		return nil
+	}
+
+	return stmt
+}
+
+// parseMethodBlockStatement parses the methods bound to a type:
+//
+//	File => {
+//	    fn close(self) { ... }
+//	}
+func (p *Parser) parseMethodBlockStatement() *ast.MethodBlockStatement {
+	stmt := ast.NewMethodBlockStatement(p.curToken)
+	p.nextToken()
+
+	if !p.expectPeek(lexer.LEFT_CURLY_BRACKET) {
+		return nil
+	}
+
+	// method names are not bindings of the enclosing scope
+	p.enterScope()
+	defer p.leaveScope()
+
+	for !p.peekTokenIs(lexer.RIGHT_CURLY_BRACKET) {
+		if !p.expectPeek(lexer.FN) {
+			return nil
+		}
+		method, ok := p.parseFunctionLiteral().(*ast.FunctionLiteral)
+		if !ok || method == nil {
+			return nil
+		}
+		if method.Name == nil {
+			p.pushError(fmt.Sprintf("[%d:%d] method of %s must have a name",
+				method.Token.Position().Line(), method.Token.Position().Column(), stmt.Type.Value))
+			return nil
+		}
+		if len(method.Parameters) == 0 || method.Parameters[0].Name.Token.Type != lexer.SELF {
+			p.pushError(fmt.Sprintf("[%d:%d] method %s of %s must take self as first parameter",
+				method.Name.Token.Position().Line(), method.Name.Token.Position().Column(),
+				method.Name.Value, stmt.Type.Value))
+		}
+		stmt.Methods = append(stmt.Methods, method)
+	}
+	p.nextToken()
+
+	return stmt
+}
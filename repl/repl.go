@@ -25,13 +25,14 @@ func Start(in io.Reader, out io.Writer) int {
 		line := scanner.Text()
 		l := lexer.New(bufio.NewReader(strings.NewReader(line)))
 		p := parser.New(l)
+		program := p.Parse()
 
 		if len(p.Errors()) > 0 {
 			printParserErrors(out, p.Errors())
 			continue
 		}
 
-		evaluated := evaluator.Eval(p.Parse(), env)
+		evaluated := evaluator.Eval(program, env)
 		if evaluated != nil {
 			io.WriteString(out, evaluated.Inspect()+"\n")
 		}